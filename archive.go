@@ -0,0 +1,336 @@
+package main
+
+// imports <<<
+import (
+	"os"
+	"io"
+	"fmt"
+	"path"
+	"sync"
+	"io/fs"
+	"strings"
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"github.com/klauspost/compress/zstd"
+) // >>>
+
+// Archive struct <<<
+type Archive struct {
+	Manifest // the entries as read from the archive headers, without checksums
+
+	Kind      string
+	files     map[string]*zip.File         // zip only, opened on demand
+	hardlinks map[string]string            // tar only, path of the link to the path of its file
+	checksums map[string]map[string]string // tar only, algorithm to path to checksum
+	lock      sync.Mutex
+}
+
+type tarFile struct {
+	// a file inside a tar archive, closing it closes the archive
+	io.Reader
+	close func()
+}
+
+func (self tarFile) Close() error { self.close(); return nil }
+// >>>
+
+// Variables <<<
+var (
+	ArchiveKinds = map[string]string{
+		".zip":     "zip",
+		".tar":     "tar",
+		".tar.gz":  "tar.gz",
+		".tgz":     "tar.gz",
+		".tar.zst": "tar.zst",
+		".tzst":    "tar.zst",
+	}
+)
+// >>>
+
+func getArchiveKind(fpath string) string {// <<<
+	// returns an empty string if the file name has no known archive extension
+	for Ext, Kind := range ArchiveKinds {
+		if strings.HasSuffix(strings.ToLower(fpath), Ext) {
+			return Kind
+		}
+	}
+	return ""
+}// >>>
+
+func normalizeArchivePath(name string, isdir bool) string {// <<<
+	// removes leading "./" and "/" and the first --strip-components folders,
+	// returns an empty string if nothing is left
+	Parts := strings.Split(strings.Trim(path.Clean("/" + name), "/"), "/")
+
+	if len(Parts) <= Arg_StripComponents || Parts[0] == "" {
+		return ""
+	}
+
+	Result := strings.Join(Parts[Arg_StripComponents:], "/")
+	if isdir {
+		Result = Result + "/"
+	}
+	return Result
+}// >>>
+
+func openTarReader(fpath string, kind string) (*tar.Reader, func(), error) {// <<<
+	// the returned function closes the file and the decompressor
+	File, Err := os.Open(fpath)
+	if Err != nil {
+		return nil, nil, Err
+	}
+
+	switch kind {
+	case "tar.gz":
+		Reader, Err := gzip.NewReader(File)
+		if Err != nil {
+			File.Close()
+			return nil, nil, Err
+		}
+		return tar.NewReader(Reader), func() { Reader.Close(); File.Close() }, nil
+	case "tar.zst":
+		Reader, Err := zstd.NewReader(File)
+		if Err != nil {
+			File.Close()
+			return nil, nil, Err
+		}
+		return tar.NewReader(Reader), func() { Reader.Close(); File.Close() }, nil
+	}
+
+	return tar.NewReader(File), func() { File.Close() }, nil
+}// >>>
+
+func addArchiveEntry(entries map[string]ManifestEntry, entry ManifestEntry) {// <<<
+	// archives don't need to contain the folders of their files, missing folders are added
+	if entry.Path == "" {
+		return
+	}
+	entries[entry.Path] = entry
+
+	for Dir := path.Dir(strings.TrimSuffix(entry.Path, "/")); Dir != "."; Dir = path.Dir(Dir) {
+		if _, Exists := entries[Dir + "/"]; Exists {
+			break
+		}
+		entries[Dir + "/"] = ManifestEntry{Path: Dir + "/", Mode: fs.ModeDir | 0755}
+	}
+}// >>>
+
+func loadArchive(fpath string) (*Archive, error) {// <<<
+	var Entries = make(map[string]ManifestEntry)
+	var Result  = Archive{
+		Manifest  : Manifest{Root: fpath},
+		Kind      : getArchiveKind(fpath),
+		files     : make(map[string]*zip.File),
+		hardlinks : make(map[string]string),
+		checksums : make(map[string]map[string]string),
+	}
+
+	if Result.Kind == "zip" {
+		Reader, Err := zip.OpenReader(fpath)
+		if Err != nil {
+			return nil, fmt.Errorf("'%s' is not a zip archive: %s", fpath, Err)
+		}
+		// the reader stays open until diffee exits
+
+		for _, f := range Reader.File {
+			Mode := f.Mode()
+			E := ManifestEntry{
+				Path    : normalizeArchivePath(f.Name, Mode.IsDir()),
+				ModTime : f.Modified,
+				Mode    : Mode,
+			}
+			if Mode.IsDir() == false {
+				E.Size = int64(f.UncompressedSize64)
+			}
+			if Mode & fs.ModeSymlink != 0 {
+				// the target of a link is stored as its content
+				Target, Err := readZipFile(f)
+				if Err != nil {
+					return nil, fmt.Errorf("could not read link '%s' in '%s': %s", f.Name, fpath, Err)
+				}
+				E.Target = Target
+			}
+			Result.files[E.Path] = f
+			addArchiveEntry(Entries, E)
+		}
+	} else {
+		Reader, Close, Err := openTarReader(fpath, Result.Kind)
+		if Err != nil {
+			return nil, fmt.Errorf("'%s' is not a %s archive: %s", fpath, Result.Kind, Err)
+		}
+		defer Close()
+
+		for {
+			Header, Err := Reader.Next()
+			if Err == io.EOF {
+				break
+			}
+			if Err != nil {
+				return nil, fmt.Errorf("'%s' is not a %s archive: %s", fpath, Result.Kind, Err)
+			}
+
+			Uid, Gid := Header.Uid, Header.Gid
+			E := ManifestEntry{
+				Path    : normalizeArchivePath(Header.Name, Header.Typeflag == tar.TypeDir),
+				ModTime : Header.ModTime,
+				Mode    : Header.FileInfo().Mode(),
+				Uid     : &Uid,
+				Gid     : &Gid,
+			}
+
+			switch Header.Typeflag {
+			case tar.TypeDir:
+			case tar.TypeReg:
+				E.Size = Header.Size
+			case tar.TypeSymlink:
+				E.Target = Header.Linkname
+			case tar.TypeLink:
+				// a hard link looks like a copy of the file it links to
+				Target, Exists := Entries[normalizeArchivePath(Header.Linkname, false)]
+				if Exists == false {
+					continue
+				}
+				E.Size = Target.Size
+				E.Mode = Target.Mode
+				Result.hardlinks[E.Path] = Target.Path
+			default:
+				continue // devices, fifos and the like
+			}
+
+			addArchiveEntry(Entries, E)
+		}
+	}
+
+	for _, E := range Entries {
+		Result.Entries = append(Result.Entries, E)
+	}
+	sortManifestEntries(Result.Entries)
+
+	Result.index = make(map[string]*ManifestEntry)
+	for i := range Result.Entries {
+		Result.index[Result.Entries[i].Path] = &Result.Entries[i]
+	}
+
+	return &Result, nil
+}// >>>
+
+func readZipFile(f *zip.File) (string, error) {// <<<
+	Reader, Err := f.Open()
+	if Err != nil {
+		return "", Err
+	}
+	defer Reader.Close()
+
+	Content, Err := io.ReadAll(Reader)
+	return string(Content), Err
+}// >>>
+
+func (self *Archive) Checksum(normpath string, algo string) (string, error) {// <<<
+	// checksums are computed from the archive content, without extracting it
+	normpath = self.resolve(normpath)
+
+	E, Exists := self.index[normpath]
+	if Exists == false {
+		return "", &fs.PathError{Op: "checksum", Path: normpath, Err: fs.ErrNotExist}
+	}
+	if E.IsDir() {
+		return "", nil
+	}
+
+	if self.Kind == "zip" {
+		Reader, Err := self.files[normpath].Open()
+		if Err != nil {
+			return "", Err
+		}
+		defer Reader.Close()
+		return getChecksumReader(Reader, algo)
+	}
+
+	// a tar archive can only be read from the start, so all files are hashed in one pass
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if _, Done := self.checksums[algo]; Done == false {
+		if Err := self.updateTarChecksums(algo); Err != nil {
+			return "", Err
+		}
+	}
+
+	if Target, IsHardlink := self.hardlinks[normpath]; IsHardlink {
+		normpath = Target
+	}
+	return self.checksums[algo][normpath], nil
+}// >>>
+
+func (self *Archive) Open(normpath string) (io.ReadCloser, error) {// <<<
+	// a tar archive is read from the start up to the file
+	normpath = self.resolve(normpath)
+	if Target, IsHardlink := self.hardlinks[normpath]; IsHardlink {
+		normpath = Target
+	}
+
+	E, Exists := self.index[normpath]
+	if Exists == false || E.IsDir() {
+		return nil, &fs.PathError{Op: "open", Path: normpath, Err: fs.ErrNotExist}
+	}
+
+	if self.Kind == "zip" {
+		return self.files[normpath].Open()
+	}
+
+	Reader, Close, Err := openTarReader(self.Root, self.Kind)
+	if Err != nil {
+		return nil, Err
+	}
+
+	for {
+		Header, Err := Reader.Next()
+		if Err != nil {
+			Close()
+			if Err == io.EOF {
+				Err = &fs.PathError{Op: "open", Path: normpath, Err: fs.ErrNotExist}
+			}
+			return nil, Err
+		}
+		if Header.Typeflag == tar.TypeReg && normalizeArchivePath(Header.Name, false) == normpath {
+			return tarFile{Reader, Close}, nil
+		}
+	}
+}// >>>
+
+func (self *Archive) updateTarChecksums(algo string) error {// <<<
+	var Checksums = make(map[string]string)
+
+	Reader, Close, Err := openTarReader(self.Root, self.Kind)
+	if Err != nil {
+		return Err
+	}
+	defer Close()
+
+	for {
+		Header, Err := Reader.Next()
+		if Err == io.EOF {
+			break
+		}
+		if Err != nil {
+			return Err
+		}
+		if Header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		FPath := normalizeArchivePath(Header.Name, false)
+		if FPath == "" {
+			continue
+		}
+		if Checksums[FPath], Err = getChecksumReader(Reader, algo); Err != nil {
+			return Err
+		}
+	}
+
+	self.checksums[algo] = Checksums
+	return nil
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
@@ -0,0 +1,158 @@
+package main
+
+// imports <<<
+import (
+	"os"
+	"io"
+	"bytes"
+	"testing"
+	"path/filepath"
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"github.com/klauspost/compress/zstd"
+) // >>>
+
+// Variables <<<
+var (
+	// the large file is bigger than the buffers of the hash readers and the decompressors
+	TestArchiveFiles = map[string][]byte{
+		"small.txt":     []byte("hello archive\n"),
+		"dir/large.bin": bytes.Repeat([]byte("0123456789abcdefghijklmnopqrstuvwxyz\n"), 10000),
+	}
+)
+// >>>
+
+func writeTestFiles(t *testing.T, dir string) {// <<<
+	for Name, Content := range TestArchiveFiles {
+		FPath := filepath.Join(dir, Name)
+		if Err := os.MkdirAll(filepath.Dir(FPath), 0755); Err != nil {
+			t.Fatal(Err)
+		}
+		if Err := os.WriteFile(FPath, Content, 0644); Err != nil {
+			t.Fatal(Err)
+		}
+	}
+}// >>>
+
+func writeTestTar(t *testing.T, w io.Writer) {// <<<
+	Writer := tar.NewWriter(w)
+	for Name, Content := range TestArchiveFiles {
+		Header := tar.Header{Name: Name, Mode: 0644, Size: int64(len(Content)), Typeflag: tar.TypeReg}
+		if Err := Writer.WriteHeader(&Header); Err != nil {
+			t.Fatal(Err)
+		}
+		if _, Err := Writer.Write(Content); Err != nil {
+			t.Fatal(Err)
+		}
+	}
+	if Err := Writer.Close(); Err != nil {
+		t.Fatal(Err)
+	}
+}// >>>
+
+func writeTestArchive(t *testing.T, fpath string) {// <<<
+	File, Err := os.Create(fpath)
+	if Err != nil {
+		t.Fatal(Err)
+	}
+	defer File.Close()
+
+	switch getArchiveKind(fpath) {
+	case "zip":
+		Writer := zip.NewWriter(File)
+		for Name, Content := range TestArchiveFiles {
+			f, Err := Writer.Create(Name)
+			if Err != nil {
+				t.Fatal(Err)
+			}
+			if _, Err := f.Write(Content); Err != nil {
+				t.Fatal(Err)
+			}
+		}
+		if Err := Writer.Close(); Err != nil {
+			t.Fatal(Err)
+		}
+	case "tar":
+		writeTestTar(t, File)
+	case "tar.gz":
+		Writer := gzip.NewWriter(File)
+		writeTestTar(t, Writer)
+		if Err := Writer.Close(); Err != nil {
+			t.Fatal(Err)
+		}
+	case "tar.zst":
+		Writer, Err := zstd.NewWriter(File)
+		if Err != nil {
+			t.Fatal(Err)
+		}
+		writeTestTar(t, Writer)
+		if Err := Writer.Close(); Err != nil {
+			t.Fatal(Err)
+		}
+	}
+}// >>>
+
+func TestArchiveChecksum(t *testing.T) {// <<<
+	// the checksum of a file in an archive must be the one of the same file on disk
+	var Dir string = t.TempDir()
+	writeTestFiles(t, filepath.Join(Dir, "files"))
+
+	for _, Name := range []string{"test.zip", "test.tar", "test.tar.gz", "test.tar.zst"} {
+		FPath := filepath.Join(Dir, Name)
+		writeTestArchive(t, FPath)
+
+		Archive, Err := loadArchive(FPath)
+		if Err != nil {
+			t.Fatal(Err)
+		}
+
+		for _, Algo := range HashAlgorithms {
+			for File := range TestArchiveFiles {
+				Expected, Err := getChecksum(filepath.Join(Dir, "files", File), Algo)
+				if Err != nil {
+					t.Fatal(Err)
+				}
+				Actual, Err := Archive.Checksum(File, Algo)
+				if Err != nil {
+					t.Fatalf("%s: %s %s: %s", Name, Algo, File, Err)
+				}
+				if Actual != Expected {
+					t.Errorf("%s: %s of %s is %s, expected %s", Name, Algo, File, Actual, Expected)
+				}
+			}
+		}
+	}
+}// >>>
+
+func TestArchiveOpen(t *testing.T) {// <<<
+	var Dir string = t.TempDir()
+
+	for _, Name := range []string{"test.zip", "test.tar.gz"} {
+		FPath := filepath.Join(Dir, Name)
+		writeTestArchive(t, FPath)
+
+		Archive, Err := loadArchive(FPath)
+		if Err != nil {
+			t.Fatal(Err)
+		}
+
+		for File, Expected := range TestArchiveFiles {
+			Reader, Err := Archive.Open(File)
+			if Err != nil {
+				t.Fatalf("%s: %s: %s", Name, File, Err)
+			}
+			Actual, Err := io.ReadAll(Reader)
+			Reader.Close()
+			if Err != nil || bytes.Equal(Actual, Expected) == false {
+				t.Errorf("%s: %s has %d bytes, expected %d: %v", Name, File, len(Actual), len(Expected), Err)
+			}
+		}
+
+		if _, Err := Archive.Open("missing.txt"); Err == nil {
+			t.Errorf("%s: missing.txt can be opened", Name)
+		}
+	}
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
@@ -0,0 +1,218 @@
+package main
+
+// imports <<<
+import (
+	"os"
+	"fmt"
+	"regexp"
+	"strings"
+	"path/filepath"
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+) // >>>
+
+// Config struct <<<
+type Config struct {
+	Theme  string                       `toml:"theme"`
+	Flags  map[string]any               `toml:"flags"`  // default values of flags by their long name
+	Themes map[string]map[string]string `toml:"themes"` // style of every state by theme name
+}
+// >>>
+
+// Variables <<<
+var (
+	// the states a theme can change, "default" are the styles in funcs.go
+	ThemeStyles = map[string]*lipgloss.Style{
+		"root":     &StyleRoot,
+		"missing":  &StyleMissing,
+		"orphan":   &StyleOrphan,
+		"bigger":   &StyleBigger,
+		"smaller":  &StyleSmaller,
+		"newer":    &StyleNewer,
+		"older":    &StyleOlder,
+		"diff":     &StyleDiff,
+		"added":    &StyleAdded,
+		"removed":  &StyleRemoved,
+		"hunk":     &StyleHunk,
+		"conflict": &StyleConflict,
+		"moved":    &StyleMoved,
+	}
+
+	// for terminals with a light background
+	Themes = map[string]map[string]string{
+		"light": {
+			"root":     "130 bold",
+			"missing":  "244",
+			"orphan":   "26",
+			"bigger":   "28",
+			"smaller":  "160",
+			"newer":    "28",
+			"older":    "160",
+			"diff":     "127",
+			"added":    "28",
+			"removed":  "160",
+			"hunk":     "31",
+			"conflict": "160 bold",
+			"moved":    "30",
+		},
+	}
+
+	ColorRegEx *regexp.Regexp = regexp.MustCompile(`^([0-9]{1,3}|#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6})$`)
+)
+// >>>
+
+func getConfigFile() string {// <<<
+	if ConfigHome := os.Getenv("XDG_CONFIG_HOME"); ConfigHome != "" {
+		return filepath.Join(ConfigHome, "diffee", "config.toml")
+	}
+	if Home, Err := os.UserHomeDir(); Err == nil {
+		return filepath.Join(Home, ".config", "diffee", "config.toml")
+	}
+	return ""
+}// >>>
+
+func readConfig(fpath string) (Config, error) {// <<<
+	// a missing config file is no error
+	var Result Config
+
+	if fpath == "" {
+		return Result, nil
+	}
+	if _, Err := os.Stat(fpath); os.IsNotExist(Err) {
+		return Result, nil
+	}
+
+	if _, Err := toml.DecodeFile(fpath, &Result); Err != nil {
+		return Result, fmt.Errorf("could not read config file '%s': %s", fpath, Err)
+	}
+	return Result, nil
+}// >>>
+
+func parseStyle(spec string) (lipgloss.Style, error) {// <<<
+	// a style is a list of words like "160 bold" or "#ffffff on 4 underline",
+	// the first color is the foreground, a color after "on" the background
+	var Result lipgloss.Style = lipgloss.NewStyle()
+	var Words  []string       = strings.Fields(spec)
+
+	for i:=0; i < len(Words); i++ {
+		switch w := Words[i]; {
+		case w == "bold":          Result = Result.Bold(true)
+		case w == "faint":         Result = Result.Faint(true)
+		case w == "italic":        Result = Result.Italic(true)
+		case w == "underline":     Result = Result.Underline(true)
+		case w == "reverse":       Result = Result.Reverse(true)
+		case w == "strikethrough": Result = Result.Strikethrough(true)
+		case w == "on" && i+1 < len(Words) && ColorRegEx.MatchString(Words[i+1]):
+			Result = Result.Background(lipgloss.Color(Words[i+1]))
+			i = i + 1
+		case ColorRegEx.MatchString(w):
+			Result = Result.Foreground(lipgloss.Color(w))
+		default:
+			return Result, fmt.Errorf("invalid style '%s', '%s' is no color or attribute", spec, w)
+		}
+	}
+
+	return Result, nil
+}// >>>
+
+func updateStyleMaps() {// <<<
+	// the maps hold copies of the styles, so they are filled again after a style changed
+	SizeStyles[Bigger]  = StyleBigger
+	SizeStyles[Smaller] = StyleSmaller
+	TimeStyles[Newer]   = StyleNewer
+	TimeStyles[Older]   = StyleOlder
+}// >>>
+
+func applyTheme(name string, themes map[string]map[string]string) error {// <<<
+	// themes of the config file win over the built-in ones, states that are not set keep the default style
+	if name == "" || name == "default" {
+		return nil
+	}
+
+	Theme, Exists := themes[name]
+	if Exists == false {
+		Theme, Exists = Themes[name]
+	}
+	if Exists == false {
+		return fmt.Errorf("unknown theme '%s'", name)
+	}
+
+	for State, Spec := range Theme {
+		Target, Exists := ThemeStyles[State]
+		if Exists == false {
+			return fmt.Errorf("theme '%s' has unknown state '%s'", name, State)
+		}
+		Style, Err := parseStyle(Spec)
+		if Err != nil {
+			return fmt.Errorf("theme '%s': %s", name, Err)
+		}
+		*Target = Style
+	}
+
+	updateStyleMaps()
+	return nil
+}// >>>
+
+func isKnownFlag(cmd *cobra.Command, name string) bool {// <<<
+	// a flag of another command is no error, the config file is shared by all commands
+	if cmd.Flags().Lookup(name) != nil || cmd.PersistentFlags().Lookup(name) != nil {
+		return true
+	}
+	for _, c := range cmd.Commands() {
+		if isKnownFlag(c, name) {
+			return true
+		}
+	}
+	return false
+}// >>>
+
+func applyConfigFlags(cmd *cobra.Command, flags map[string]any) error {// <<<
+	// flags given on the command line win over the config file
+	for Name, Value := range flags {
+		Flag := cmd.Flags().Lookup(Name)
+		if Flag == nil {
+			if isKnownFlag(cmd.Root(), Name) == false {
+				return fmt.Errorf("unknown flag '%s' in config file", Name)
+			}
+			continue
+		}
+		if Flag.Changed {
+			continue
+		}
+
+		Values, IsList := Value.([]any)
+		if IsList == false {
+			Values = []any{Value}
+		}
+		for _, v := range Values {
+			if Err := Flag.Value.Set(fmt.Sprint(v)); Err != nil {
+				return fmt.Errorf("invalid value for flag '%s' in config file: %s", Name, Err)
+			}
+		}
+	}
+	return nil
+}// >>>
+
+func loadConfig(cmd *cobra.Command) {// <<<
+	Config, Err := readConfig(getConfigFile())
+	if Err != nil {
+		printError(Err.Error())
+		os.Exit(CMDLINE)
+	}
+
+	if Err := applyConfigFlags(cmd, Config.Flags); Err != nil {
+		printError(Err.Error())
+		os.Exit(CMDLINE)
+	}
+
+	if Arg_Theme == "" {
+		Arg_Theme = Config.Theme
+	}
+	if Err := applyTheme(Arg_Theme, Config.Themes); Err != nil {
+		printError(Err.Error())
+		os.Exit(CMDLINE)
+	}
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
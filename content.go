@@ -0,0 +1,237 @@
+package main
+
+// imports <<<
+import (
+	"io"
+	"fmt"
+	"bytes"
+	"strings"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pmezard/go-difflib/difflib"
+	"golang.org/x/term"
+	"diffee/tree"
+) // >>>
+
+// Variables <<<
+var (
+	ContentContext  int = 3    // number of unchanged lines around a change
+	BinarySniffSize int = 8000 // same amount of bytes git and diff look at
+	TabWidth        int = 4
+)
+// >>>
+
+func isBinaryFile(root string, normpath string) (bool, error) {// <<<
+	File, Err := openPath(root, normpath)
+	if Err != nil {
+		return false, Err
+	}
+	defer File.Close()
+
+	Buffer := make([]byte, BinarySniffSize)
+	n, Err := io.ReadFull(File, Buffer)
+	if Err != nil && Err != io.EOF && Err != io.ErrUnexpectedEOF {
+		return false, Err
+	}
+
+	return bytes.IndexByte(Buffer[:n], 0) != -1, nil
+}// >>>
+
+func readLines(root string, normpath string) ([]string, error) {// <<<
+	File, Err := openPath(root, normpath)
+	if Err != nil {
+		return nil, Err
+	}
+	defer File.Close()
+
+	Content, Err := io.ReadAll(File)
+	if Err != nil {
+		return nil, Err
+	}
+
+	Text := strings.ReplaceAll(string(Content), "\r\n", "\n")
+	Text  = strings.TrimSuffix(Text, "\n")
+	if Text == "" {
+		return []string{}, nil
+	}
+
+	return strings.Split(Text, "\n"), nil
+}// >>>
+
+func getHunkHeader(group []difflib.OpCode) string {// <<<
+	First, Last := group[0], group[len(group)-1]
+
+	FormatRange := func(start int, stop int) string {
+		// Per the diff spec, empty ranges begin at the line just before the range
+		if stop-start == 1 {
+			return fmt.Sprintf("%d", start+1)
+		} else if stop-start == 0 {
+			return fmt.Sprintf("%d,0", start)
+		}
+		return fmt.Sprintf("%d,%d", start+1, stop-start)
+	}
+
+	return StyleHunk.Render(fmt.Sprintf("@@ -%s +%s @@", FormatRange(First.I1, Last.I2), FormatRange(First.J1, Last.J2)))
+}// >>>
+
+func renderUnifiedDiff(leftlines []string, rightlines []string, groups [][]difflib.OpCode) []string {// <<<
+	var Lines []string
+
+	for _, Group := range groups {
+		Lines = append(Lines, getHunkHeader(Group))
+		for _, Op := range Group {
+			if Op.Tag == 'e' {
+				for _, l := range leftlines[Op.I1:Op.I2] {
+					// the styles expand tabs of the changed lines, so the unchanged ones are expanded as well
+					Lines = append(Lines, " " + strings.ReplaceAll(l, "\t", strings.Repeat(" ", TabWidth)))
+				}
+				continue
+			}
+			if Op.Tag == 'r' || Op.Tag == 'd' {
+				for _, l := range leftlines[Op.I1:Op.I2] {
+					Lines = append(Lines, StyleRemoved.Render("-" + l))
+				}
+			}
+			if Op.Tag == 'r' || Op.Tag == 'i' {
+				for _, l := range rightlines[Op.J1:Op.J2] {
+					Lines = append(Lines, StyleAdded.Render("+" + l))
+				}
+			}
+		}
+	}
+
+	return Lines
+}// >>>
+
+func renderSideBySideDiff(leftlines []string, rightlines []string, groups [][]difflib.OpCode, indent int) []string {// <<<
+	// indent is the width of the tree lines in front of the diff
+	var Lines []string
+	var ColumnWidth int = 80
+
+	TermWidth, _, Err := term.GetSize(0)
+	if Err == nil {
+		ColumnWidth = max(1, (TermWidth-indent-3)/2)
+	}
+
+	Cell := func(text string, style lipgloss.Style) string {
+		Text := ansi.Truncate(strings.ReplaceAll(text, "\t", strings.Repeat(" ", TabWidth)), ColumnWidth, "…")
+		return style.Render(Text + strings.Repeat(" ", max(0, ColumnWidth-lipgloss.Width(Text))))
+	}
+
+	for _, Group := range groups {
+		Lines = append(Lines, getHunkHeader(Group))
+		for _, Op := range Group {
+			LeftCount  := Op.I2 - Op.I1
+			RightCount := Op.J2 - Op.J1
+
+			for i:=0; i < max(LeftCount, RightCount); i++ {
+				var Left  string = Cell("", lipgloss.NewStyle())
+				var Right string = Cell("", lipgloss.NewStyle())
+				var Sep   string = " │ "
+
+				if Op.Tag == 'e' {
+					Left  = Cell(leftlines[Op.I1+i] , lipgloss.NewStyle())
+					Right = Cell(rightlines[Op.J1+i], lipgloss.NewStyle())
+				} else {
+					if i < LeftCount {
+						Left = Cell(leftlines[Op.I1+i], StyleRemoved)
+					}
+					if i < RightCount {
+						Right = Cell(rightlines[Op.J1+i], StyleAdded)
+					}
+					switch {
+					case i < LeftCount && i < RightCount: Sep = " ≠ "
+					case i < LeftCount:                   Sep = " < "
+					default:                              Sep = " > "
+					}
+				}
+
+				Lines = append(Lines, Left + Sep + Right)
+			}
+		}
+	}
+
+	return Lines
+}// >>>
+
+func getContentDiff(leftroot string, rightroot string, normpath string, indent int) ([]string, error) {// <<<
+
+	for _, r := range []string{leftroot, rightroot} {
+		IsBinary, Err := isBinaryFile(r, normpath)
+		if Err != nil {
+			return nil, Err
+		}
+		if IsBinary {
+			return []string{fmt.Sprintf("binary files %s and %s differ", leftroot + normpath, rightroot + normpath)}, nil
+		}
+	}
+
+	LeftLines, Err := readLines(leftroot, normpath)
+	if Err != nil {
+		return nil, Err
+	}
+	RightLines, Err := readLines(rightroot, normpath)
+	if Err != nil {
+		return nil, Err
+	}
+
+	Groups := difflib.NewMatcher(LeftLines, RightLines).GetGroupedOpCodes(ContentContext)
+	if len(Groups) == 0 { // e.g. only the line endings changed
+		return nil, nil
+	}
+
+	if Arg_Content == "side-by-side" {
+		return renderSideBySideDiff(LeftLines, RightLines, Groups, indent), nil
+	}
+
+	return renderUnifiedDiff(LeftLines, RightLines, Groups), nil
+}// >>>
+
+func hasContentDiff(E *Entry) bool {// <<<
+	// only files that exist on both sides have a content to compare
+	return E.IsDir == false && E.IsOrphan["left"] == false && E.IsOrphan["right"] == false &&
+	       isLinkEntry(E) == false && shouldHideEntry(E) == false && isSameEntry(E) == false
+}// >>>
+
+func getContentIndent(line string, text string) string {// <<<
+	// continues the tree lines in front of an entry, so its diff is shown below its name
+	Prefix := strings.TrimSuffix(line, text)
+	Prefix  = strings.ReplaceAll(Prefix, "├── ", "│   ")
+	return strings.ReplaceAll(Prefix, "└── ", "    ")
+}// >>>
+
+func addContentDiffs(contents *[]Entry, lines []string, treelines []string, nodes []*tree.Node) []string {// <<<
+	// inserts the line diff of every differing file below its line of the side-by-side output,
+	// treelines and nodes belong to the left column and are in the same order as lines
+	var Result []string
+	var Left   string = "left"
+	var Right  string = "right"
+
+	if Arg_Swap {
+		Left, Right = Right, Left
+	}
+
+	for i, n := range nodes {
+		Result = append(Result, lines[i])
+
+		E, IsEntry := n.GetData().(*Entry)
+		if IsEntry == false || hasContentDiff(E) == false {
+			continue
+		}
+
+		Indent := getContentIndent(treelines[i], n.GetText())
+
+		Diff, Err := getContentDiff((*contents)[0].Path[Left], (*contents)[0].Path[Right], E.NormPath, lipgloss.Width(Indent))
+		if Err != nil {
+			printError(fmt.Sprintf("could not diff '%s': %s", E.NormPath, Err))
+			continue
+		}
+		for _, l := range Diff {
+			Result = append(Result, Indent + l)
+		}
+	}
+
+	return Result
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
@@ -0,0 +1,95 @@
+package main
+
+// imports <<<
+import (
+	"fmt"
+	"strconv"
+) // >>>
+
+func getActiveCriteria() []string {// <<<
+	// the order is also the order of the --info suffix
+	var Criteria []string
+
+	if Arg_Size  { Criteria = append(Criteria, "size")  }
+	if Arg_Time  { Criteria = append(Criteria, "time")  }
+	if Arg_Mode  { Criteria = append(Criteria, "mode")  }
+	if Arg_Owner { Criteria = append(Criteria, "owner") }
+	if Arg_Bytes { Criteria = append(Criteria, "bytes") }
+	if Arg_Hash != "" { Criteria = append(Criteria, "hash") }
+
+	return Criteria
+}// >>>
+
+func isSameByCriterion(E *Entry, criterion string) bool {// <<<
+	switch criterion {
+	case "size":
+		return E.SizeDiff["left"] == SameSize && E.SizeDiff["right"] == SameSize
+	case "time":
+		return E.TimeDiff["left"] == SameTime && E.TimeDiff["right"] == SameTime
+	case "mode":
+		return isSameMode(E)
+	case "owner":
+		return isSameOwner(E)
+	}
+	// bytes and hash both fill IsDiff
+	return !E.IsDiff
+}// >>>
+
+func formatSize(size int64) string {// <<<
+	var Units = []string{"B", "KB", "MB", "GB", "TB"}
+	var Value float64 = float64(size)
+	var u int = 0
+
+	for ; (Value >= 1024 || Value <= -1024) && u < len(Units)-1; u++ {
+		Value = Value / 1024
+	}
+
+	if u == 0 {
+		return fmt.Sprintf("%d B", size)
+	}
+	return fmt.Sprintf("%.1f %s", Value, Units[u])
+}// >>>
+
+func getCriteriaInfo(E *Entry, side string) []string {// <<<
+	// one short note per criterion that differs
+	var Info  []string
+	var Other string = "right"
+
+	if side == "right" {
+		Other = "left"
+	}
+
+	for _, c := range getActiveCriteria() {
+		if isSameByCriterion(E, c) {
+			continue
+		}
+
+		switch c {
+		case "size":
+			Delta := E.Size[side] - E.Size[Other]
+			if Delta >= 0 {
+				Info = append(Info, "+" + formatSize(Delta))
+			} else {
+				Info = append(Info, "-" + formatSize(-Delta))
+			}
+		case "time":
+			Info = append(Info, TimeDiffNames[E.TimeDiff[side]])
+		case "mode":
+			Info = append(Info, getModeBits(E.Mode[side]).String())
+		case "owner":
+			Info = append(Info, strconv.Itoa(E.Uid[side]) + ":" + strconv.Itoa(E.Gid[side]))
+		case "bytes":
+			if E.DiffOffset >= 0 {
+				Info = append(Info, "byte " + strconv.FormatInt(E.DiffOffset, 10) + "≠")
+			} else {
+				Info = append(Info, "bytes≠")
+			}
+		case "hash":
+			Info = append(Info, "hash≠")
+		}
+	}
+
+	return Info
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
@@ -3,6 +3,7 @@ package main
 // imports <<<
 import (
 	"os"
+	"io"
 	"io/fs"
 	"fmt"
 	"sort"
@@ -12,9 +13,13 @@ import (
 	"regexp"
 	"strings"
 	"strconv"
-	"github.com/codingsince1985/checksum"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"runtime"
 	"github.com/charmbracelet/lipgloss"
 	"golang.org/x/term"
+	"github.com/bmatcuk/doublestar/v4"
 	"diffee/tree"
 ) // >>>
 
@@ -42,12 +47,21 @@ type Entry struct {
 	IsDir      bool
 	IsDotfile  bool
 	IsDiff     bool
+	DiffOffset int64 // first differing byte with --bytes, -1 if unknown
+	MergeState MergeState // only set with --base
+	MovePath   string // NormPath of the matching orphan on the other side, only set with --moves
+	Similarity int    // percent of equal lines of a moved file, 100 if the content is identical
 
 	// different for left and right
 	Path       map[string]string
 	Size       map[string]int64
 	ModTime    map[string]time.Time
 	Checksum   map[string]string
+	Mode       map[string]fs.FileMode
+	Uid        map[string]int // -1 if unknown
+	Gid        map[string]int // -1 if unknown
+	IsLink     map[string]bool
+	LinkTarget map[string]string
 
 	IsMissing  map[string]bool
 	IsOrphan   map[string]bool
@@ -71,6 +85,11 @@ var (
 	StyleNewer   = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
 	StyleOlder   = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
 	StyleDiff    = lipgloss.NewStyle().Foreground(lipgloss.Color("13"))
+	StyleAdded   = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	StyleRemoved = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	StyleHunk    = lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
+	StyleConflict = lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true)
+	StyleMoved   = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
 
 	SizeStyles = map[SizeDiffState]lipgloss.Style{
 		SameSize: lipgloss.NewStyle(),
@@ -85,6 +104,14 @@ var (
 	}
 
 	RightSideOffset int = 10
+
+	// a side or a file could not be read completely, so the result is not trustworthy,
+	// it is set by the parallel workers as well
+	HasWalkErrors atomic.Bool
+
+	// modification times that differ by at most this much are the same, set via --time-tolerance
+	TimeTolerance time.Duration = 0
+	TimeGranularities = []time.Duration{2*time.Second, time.Second, 10*time.Millisecond, time.Millisecond, time.Microsecond}
 )
 // >>>
 
@@ -101,12 +128,26 @@ func setNoColor() {// <<<
 	StyleNewer   = lipgloss.NewStyle()
 	StyleOlder   = lipgloss.NewStyle()
 	StyleDiff    = lipgloss.NewStyle()
+	StyleAdded   = lipgloss.NewStyle()
+	StyleRemoved = lipgloss.NewStyle()
+	StyleHunk    = lipgloss.NewStyle()
+	StyleConflict = lipgloss.NewStyle()
+	StyleMoved   = lipgloss.NewStyle()
+	updateStyleMaps()
 }// >>>
 
 func isDir(dirpath string) bool {// <<<
 	return dirpath[len(dirpath)-1:] == "/"
 }// >>>
 
+func isRegularFile(fpath string) bool {// <<<
+	fileInfo, err := os.Stat(fpath)
+	if err != nil {
+		return false
+	}
+	return fileInfo.Mode().IsRegular()
+}// >>>
+
 func isDirectory(dirpath string) bool {// <<<
 	fileInfo, err := os.Stat(dirpath)
 	if err != nil {
@@ -115,255 +156,568 @@ func isDirectory(dirpath string) bool {// <<<
 	return fileInfo.IsDir()
 }// >>>
 
-func getUnionSetOfDirContents(left string, right string, ListOfPaths *[]string) {// <<<
+func addToSetOfPaths(SetOfPaths map[string]struct{}, fpath string, isdir bool) error {// <<<
+	// fpath is relative to the root and has no trailing slash,
+	// returns filepath.SkipDir if the content of a folder shall be skipped
 
-	var Root string
-	var SetOfPaths = make(map[string]struct{})
-
-	WalkerFunc := func(fpath string, info fs.FileInfo, err error) error {
-		if err != nil {
-			return err
+	if Arg_Depth != 0 {
+		if strings.Count(fpath, string(os.PathSeparator)) >= Arg_Depth {
+			return filepath.SkipDir
 		}
+	}
 
-		if fpath == Root {
-			SetOfPaths["."] = struct{}{}
-			return nil
+	if Arg_All == false {
+		NameChunk := NameRegEx.FindString(fpath)
+		if NameChunk[:1] == "." {
+			if isdir {
+				return filepath.SkipDir
+			} else {
+				return nil
+			}
 		}
+	}
 
-		fpath = path.Clean(strings.Replace(fpath, Root, "", 1))
+	if isdir {
+		fpath = fpath + "/"
+	}
 
-		if Arg_Depth != 0 {
-			if strings.Count(fpath, string(os.PathSeparator)) >= Arg_Depth {
-				return filepath.SkipDir
+	if len(Arg_Include) > 0 || len(Arg_IncludeGlob) > 0 {
+		MatchFound := false
+		for in:=0 ; in < len(Arg_Include) ; in++ {
+			Match := Arg_Include[in].FindString(fpath)
+			if Match != "" {
+				MatchFound = true
+			}
+		}
+		for _, g := range Arg_IncludeGlob {
+			if doublestar.MatchUnvalidated(g, fpath) { // validated by Globs.Set()
+				MatchFound = true
 			}
 		}
+		if MatchFound == false {
+			return nil
+		}
+	}
 
-		if Arg_All == false {
-			NameChunk := NameRegEx.FindString(fpath)
-			if NameChunk[:1] == "." {
-				if info.IsDir() {
+	if len(Arg_Exclude) > 0 {
+		for ex:=0 ; ex < len(Arg_Exclude) ; ex++ {
+			Match := Arg_Exclude[ex].FindString(fpath)
+			if Match != "" {
+				if isdir {
 					return filepath.SkipDir
 				} else {
 					return nil
 				}
 			}
 		}
+	}
 
-		if info.IsDir() {
-			fpath = fpath + "/"
-		}
-
-		if len(Arg_Include) > 0 {
-			MatchFound := false
-			for in:=0 ; in < len(Arg_Include) ; in++ {
-				Match := Arg_Include[in].FindString(fpath)
-				if Match != "" {
-					MatchFound = true
-				}
-			}
-			if MatchFound == false {
+	for _, g := range Arg_ExcludeGlob {
+		if doublestar.MatchUnvalidated(g, fpath) {
+			if isdir {
+				return filepath.SkipDir
+			} else {
 				return nil
 			}
 		}
+	}
 
-		if len(Arg_Exclude) > 0 {
-			for ex:=0 ; ex < len(Arg_Exclude) ; ex++ {
-				Match := Arg_Exclude[ex].FindString(fpath)
-				if Match != "" {
-					if info.IsDir() {
-						return filepath.SkipDir
-					} else {
-						return nil
-					}
-				}
-			}
+	if Arg_Files {
+		if isdir {
+			return nil
 		}
+	}
 
-		if Arg_Files {
-			if info.IsDir() {
-				return nil
+	if Arg_Folders {
+		if isdir == false {
+			return nil
+		}
+	}
+
+	if Arg_Files || (len(Arg_Include) > 0) || (len(Arg_IncludeGlob) > 0) {
+		SplitPath := strings.SplitAfter(fpath, "/")
+		CombinedPath := ""
+		for i:=0; i < len(SplitPath); i++ {
+			CombinedPath = CombinedPath + SplitPath[i]
+			SetOfPaths[CombinedPath] = struct{}{}
+		}
+		return nil
+	}
+
+	SetOfPaths[fpath] = struct{}{}
+
+	return nil
+}// >>>
+
+func getDirContents(root string, SetOfPaths map[string]struct{}, Rules *IgnoreRules) error {// <<<
+	// Rules collects the rules of all ignore files the side has, the union of all sides is filtered with them
+	return getSide(root).Contents(SetOfPaths, Rules)
+}// >>>
+
+func walkDir(root string, dir string, ancestors []string, rules IgnoreRules, SetOfPaths map[string]struct{}, Collected *IgnoreRules) error {// <<<
+	// walks in lexical order like filepath.Walk, but handles symlinks according to --symlinks,
+	// ancestors are the real paths of the folders above and used to detect loops
+
+	DirEntries, Err := os.ReadDir(root + dir)
+	if Err != nil {
+		return Err
+	}
+
+	if Arg_Symlinks == "follow" {
+		ancestors = append(ancestors, getRealPath(root + dir))
+	}
+
+	// the rules of the .gitignore in this folder are the ones after the inherited rules
+	DirRules := getDirIgnoreRules(root, dir, rules)
+	*Collected = append(*Collected, DirRules[len(rules):]...)
+	rules = DirRules
+
+	for _, d := range DirEntries {
+		FPath := path.Join(dir, d.Name())
+		IsDir := d.IsDir()
+
+		if d.Type() & fs.ModeSymlink != 0 {
+			switch Arg_Symlinks {
+			case "ignore":
+				continue
+			case "follow":
+				// broken links and links to a folder above are kept as link
+				FileInfo, Err := os.Stat(root + FPath)
+				IsDir = Err == nil && FileInfo.IsDir() && slices.Contains(ancestors, getRealPath(root + FPath)) == false
 			}
 		}
 
-		if Arg_Folders {
-			if info.IsDir() == false {
-				return nil
+		// git never looks into its own folder
+		if isIgnoredPath(rules, FPath, IsDir) || isGitFolder(FPath, IsDir) {
+			continue
+		}
+
+		if addToSetOfPaths(SetOfPaths, FPath, IsDir) == filepath.SkipDir {
+			if IsDir {
+				continue
 			}
+			return nil // like filepath.Walk, the remaining files of the folder are skipped
 		}
 
-		if Arg_Files || (len(Arg_Include) > 0) {
-			SplitPath := strings.SplitAfter(fpath, "/")
-			CombinedPath := ""
-			for i:=0; i < len(SplitPath); i++ {
-				CombinedPath = CombinedPath + SplitPath[i]
-				SetOfPaths[CombinedPath] = struct{}{}
+		if IsDir {
+			if Err := walkDir(root, FPath, ancestors, rules, SetOfPaths, Collected); Err != nil {
+				return Err
 			}
-			return nil
 		}
+	}
 
-		SetOfPaths[fpath] = struct{}{}
+	return nil
+}// >>>
 
-		return nil
+func getRealPath(fpath string) string {// <<<
+	RealPath, Err := filepath.EvalSymlinks(fpath)
+	if Err != nil {
+		return fpath
 	}
-
-	Root = left
-	err := filepath.Walk(left, WalkerFunc)
-	if err != nil {
-		fmt.Println(err)
+	AbsPath, Err := filepath.Abs(RealPath)
+	if Err != nil {
+		return RealPath
 	}
-	Root = right
-	err = filepath.Walk(right, WalkerFunc)
-	if err != nil {
-		fmt.Println(err)
+	return AbsPath
+}// >>>
+
+func getOwner(info fs.FileInfo) (int, int) {// <<<
+	// returns uid and gid, -1 if they are unknown
+	if E, IsManifest := info.Sys().(*ManifestEntry); IsManifest {
+		if E.Uid == nil || E.Gid == nil {
+			return -1, -1
+		}
+		return *E.Uid, *E.Gid
 	}
+	return getFileOwner(info)
+}// >>>
 
-	for p := range SetOfPaths {
-		*ListOfPaths = append(*ListOfPaths, p)
+func statPath(root string, normpath string) (fs.FileInfo, error) {// <<<
+	FileInfo, Err := getSide(root).Stat(normpath)
+	if Err == nil && Arg_Symlinks == "ignore" && FileInfo.Mode() & fs.ModeSymlink != 0 {
+		return nil, &fs.PathError{Op: "stat", Path: root + normpath, Err: fs.ErrNotExist}
 	}
-	sort.Strings(*ListOfPaths)
+	return FileInfo, Err
+}// >>>
 
+func readLink(root string, normpath string) string {// <<<
+	return getSide(root).Readlink(normpath)
 }// >>>
 
-func getDirContentInformation(leftroot string, rightroot string, unionset *[]string, content *[]Entry) {// <<<
+func getPathChecksum(root string, normpath string, algo string) (string, error) {// <<<
+	return getSide(root).Checksum(normpath, algo)
+}// >>>
+
+func openPath(root string, normpath string) (io.ReadCloser, error) {// <<<
+	return getSide(root).Open(normpath)
+}// >>>
 
-	*content = append(*content, Entry{ Path: map[string]string{ "left":  leftroot, "right": rightroot } } )
+func getUnionSetOfDirContents(roots []string, ListOfPaths *[]string) {// <<<
+
+	var SetsOfPaths  = make([]map[string]struct{}, len(roots))
+	var RuleSets     = make([]IgnoreRules, len(roots))
+	var Errors       = make([]error, len(roots))
+	var WaitGroup      sync.WaitGroup
+
+	// all sides are walked at the same time, each into its own set
+	for i := range roots {
+		SetsOfPaths[i] = make(map[string]struct{})
+		WaitGroup.Add(1)
+		go func() {
+			defer WaitGroup.Done()
+			Errors[i] = getDirContents(roots[i], SetsOfPaths[i], &RuleSets[i])
+		}()
+	}
+	WaitGroup.Wait()
 
-	for i:=1 ; i < len(*unionset) ; i++ {
+	// stdout is kept clean for the json and html output
+	for _, err := range Errors {
+		if err != nil {
+			printError(err.Error())
+			HasWalkErrors.Store(true)
+		}
+	}
 
-		LeftPath  := leftroot  + (*unionset)[i]
-		RightPath := rightroot + (*unionset)[i]
-		NormPath  := (*unionset)[i]
-		IsDotfile := false
+	for i:=1; i < len(SetsOfPaths); i++ {
+		for p := range SetsOfPaths[i] {
+			SetsOfPaths[0][p] = struct{}{}
+		}
+	}
 
-		Name := NameRegEx.FindString(NormPath)
-		if Name[:1] == "." {
-			IsDotfile = true
+	// a path ignored on one side is ignored on all sides, so it doesn't show up as orphan
+	for p := range SetsOfPaths[0] {
+		if isIgnoredInUnion(p, RuleSets) == false {
+			*ListOfPaths = append(*ListOfPaths, p)
 		}
+	}
+	sort.Strings(*ListOfPaths)
 
-		LeftFileInfo , LErr := os.Stat(LeftPath)
-		RightFileInfo, RErr := os.Stat(RightPath)
+}// >>>
 
-		var IsDir          bool      = isDir(Name)
+func getEntry(leftroot string, rightroot string, normpath string) Entry {// <<<
 
-		var LeftSize       int64     = 0
-		var LeftModTime    time.Time = time.Time{}
-		var LeftChecksum   string    = ""
-		var LeftIsOrphan   bool      = false
-		var LeftIsMissing  bool      = false
-		var LeftSizeState  SizeDiffState = SameSize
-		var LeftTimeState  TimeDiffState = SameTime
+	LeftPath  := leftroot  + normpath
+	RightPath := rightroot + normpath
+	NormPath  := normpath
+	IsDotfile := false
 
-		var RightSize      int64     = 0
-		var RightModTime   time.Time = time.Time{}
-		var RightChecksum  string    = ""
-		var RightIsOrphan  bool      = false
-		var RightIsMissing bool      = false
-		var RightSizeState  SizeDiffState = SameSize
-		var RightTimeState  TimeDiffState = SameTime
+	Name := NameRegEx.FindString(NormPath)
+	if Name[:1] == "." {
+		IsDotfile = true
+	}
 
-		if LErr != nil {
-			LeftIsMissing = true
-		} else if IsDir != LeftFileInfo.IsDir() {
-			LeftIsMissing = true
-		} else {
-			// println(LeftPath, NormPath, Name, LeftFileInfo.Name())
-			if IsDir == false {
-				LeftSize       = LeftFileInfo.Size()
-				LeftModTime    = LeftFileInfo.ModTime()
-				LeftChecksum,_ = checksum.CRC32(LeftPath)
-			}
+	LeftFileInfo , LErr := statPath(leftroot , normpath)
+	RightFileInfo, RErr := statPath(rightroot, normpath)
+
+	var IsDir          bool      = isDir(Name)
+
+	var LeftSize       int64     = 0
+	var LeftModTime    time.Time = time.Time{}
+	var LeftChecksum   string    = ""
+	var LeftIsOrphan   bool      = false
+	var LeftIsMissing  bool      = false
+	var LeftMode       fs.FileMode = 0
+	var LeftUid        int       = -1
+	var LeftGid        int       = -1
+	var LeftIsLink     bool      = false
+	var LeftLinkTarget string    = ""
+	var LeftSizeState  SizeDiffState = SameSize
+	var LeftTimeState  TimeDiffState = SameTime
+
+	var RightSize      int64     = 0
+	var RightModTime   time.Time = time.Time{}
+	var RightChecksum  string    = ""
+	var RightIsOrphan  bool      = false
+	var RightIsMissing bool      = false
+	var RightMode      fs.FileMode = 0
+	var RightUid       int       = -1
+	var RightGid       int       = -1
+	var RightIsLink     bool     = false
+	var RightLinkTarget string   = ""
+	var RightSizeState  SizeDiffState = SameSize
+	var RightTimeState  TimeDiffState = SameTime
+
+	if LErr != nil {
+		LeftIsMissing = true
+	} else if IsDir != LeftFileInfo.IsDir() {
+		LeftIsMissing = true
+	} else {
+		// println(LeftPath, NormPath, Name, LeftFileInfo.Name())
+		LeftMode         = LeftFileInfo.Mode()
+		LeftUid, LeftGid = getOwner(LeftFileInfo)
+		if LeftMode & fs.ModeSymlink != 0 {
+			LeftIsLink     = true
+			LeftLinkTarget = readLink(leftroot, normpath)
 		}
+		if IsDir == false {
+			LeftSize       = LeftFileInfo.Size()
+			LeftModTime    = LeftFileInfo.ModTime()
+		}
+	}
 
-		if RErr != nil {
-			RightIsMissing = true
-		} else if IsDir != RightFileInfo.IsDir() {
-			RightIsMissing = true
-		} else {
-			// println(RightPath, NormPath, Name, RightFileInfo.Name())
-			if IsDir == false {
-				RightSize       = RightFileInfo.Size()
-				RightModTime    = RightFileInfo.ModTime()
-				RightChecksum,_ = checksum.CRC32(RightPath)
-			}
+	if RErr != nil {
+		RightIsMissing = true
+	} else if IsDir != RightFileInfo.IsDir() {
+		RightIsMissing = true
+	} else {
+		// println(RightPath, NormPath, Name, RightFileInfo.Name())
+		RightMode          = RightFileInfo.Mode()
+		RightUid, RightGid = getOwner(RightFileInfo)
+		if RightMode & fs.ModeSymlink != 0 {
+			RightIsLink     = true
+			RightLinkTarget = readLink(rightroot, normpath)
+		}
+		if IsDir == false {
+			RightSize       = RightFileInfo.Size()
+			RightModTime    = RightFileInfo.ModTime()
 		}
+	}
 
-		if LeftIsMissing {
-			RightIsOrphan = true
-		} else {
-			if IsDir == false {
-				if LeftSize > RightSize {
-					LeftSizeState = Bigger
-				} else if LeftSize < RightSize {
-					LeftSizeState = Smaller
-				}
+	if LeftIsMissing {
+		RightIsOrphan = true
+	} else {
+		if IsDir == false {
+			if LeftSize > RightSize {
+				LeftSizeState = Bigger
+			} else if LeftSize < RightSize {
+				LeftSizeState = Smaller
+			}
 
-				if LeftModTime.After(RightModTime) {
-					LeftTimeState = Newer
-				} else if LeftModTime.Before(RightModTime) {
-					LeftTimeState = Older
-				}
+			LeftTimeState = getTimeDiffState(LeftModTime, RightModTime)
+		}
+	}
+
+	if RightIsMissing {
+		LeftIsOrphan = true
+	} else {
+		if IsDir == false {
+			if RightSize > LeftSize {
+				RightSizeState = Bigger
+			} else if RightSize < LeftSize {
+				RightSizeState = Smaller
 			}
+
+			RightTimeState = getTimeDiffState(RightModTime, LeftModTime)
 		}
+	}
 
-		if RightIsMissing {
-			LeftIsOrphan = true
-		} else {
-			if IsDir == false {
-				if RightSize > LeftSize {
-					RightSizeState = Bigger
-				} else if RightSize < LeftSize {
-					RightSizeState = Smaller
-				}
+	NewEntry := Entry {
+
+		NormPath  : NormPath,
+		Name      : Name,
+		IsDir     : IsDir,
+		IsDotfile : IsDotfile,
+		IsDiff    : (IsDir == false) && (LeftIsMissing || RightIsMissing || LeftSize != RightSize ||
+		            LeftIsLink != RightIsLink || LeftLinkTarget != RightLinkTarget),
+		DiffOffset: -1,
+
+		Path     : map[string]string        { "left": LeftPath     , "right": RightPath      },
+		Size     : map[string]int64         { "left": LeftSize     , "right": RightSize      },
+		ModTime  : map[string]time.Time     { "left": LeftModTime  , "right": RightModTime   },
+		Checksum : map[string]string        { "left": LeftChecksum , "right": RightChecksum  },
+		Mode     : map[string]fs.FileMode   { "left": LeftMode     , "right": RightMode      },
+		Uid      : map[string]int           { "left": LeftUid      , "right": RightUid       },
+		Gid      : map[string]int           { "left": LeftGid      , "right": RightGid       },
+		IsLink   : map[string]bool          { "left": LeftIsLink   , "right": RightIsLink    },
+		LinkTarget: map[string]string       { "left": LeftLinkTarget, "right": RightLinkTarget },
+		IsMissing: map[string]bool          { "left": LeftIsMissing, "right": RightIsMissing },
+		IsOrphan : map[string]bool          { "left": LeftIsOrphan , "right": RightIsOrphan  },
+		SizeDiff : map[string]SizeDiffState { "left": LeftSizeState, "right": RightSizeState },
+		TimeDiff : map[string]TimeDiffState { "left": LeftTimeState, "right": RightTimeState }}
+
+	return NewEntry
+}// >>>
 
-				if RightModTime.After(LeftModTime) {
-					RightTimeState = Newer
-				} else if RightModTime.Before(LeftModTime) {
-					RightTimeState = Older
-				}
+func getTimeDiffState(this time.Time, other time.Time) TimeDiffState {// <<<
+	Delta := this.Sub(other)
+	if Delta > TimeTolerance {
+		return Newer
+	} else if Delta < -TimeTolerance {
+		return Older
+	}
+	return SameTime
+}// >>>
+
+func getTimeGranularity(content *[]Entry, side string) time.Duration {// <<<
+	// returns the coarsest granularity all modification times of a side are a multiple of,
+	// e.g. 2s for FAT or 1s for copies through zip
+	for _, g := range TimeGranularities {
+		IsMultiple := true
+		for i:=1; i < len(*content) && IsMultiple; i++ {
+			E := &(*content)[i]
+			if E.IsDir == false && E.IsMissing[side] == false && E.ModTime[side].UnixNano() % int64(g) != 0 {
+				IsMultiple = false
 			}
 		}
+		if IsMultiple {
+			return g
+		}
+	}
+	return 0
+}// >>>
+
+func updateTimeDiffs(content *[]Entry) {// <<<
+	for i:=1; i < len(*content); i++ {
+		E := &(*content)[i]
+		if E.IsDir || E.IsMissing["left"] || E.IsMissing["right"] {
+			continue
+		}
+		E.TimeDiff["left"]  = getTimeDiffState(E.ModTime["left"] , E.ModTime["right"])
+		E.TimeDiff["right"] = getTimeDiffState(E.ModTime["right"], E.ModTime["left"])
+	}
+}// >>>
+
+func getDirContentInformation(leftroot string, rightroot string, unionset *[]string, content *[]Entry) {// <<<
+
+	// the root is no path of the union, it always comes first,
+	// every worker writes to its own index, so the order of the union set is kept
+	Entries := make([]Entry, len(*unionset)+1)
+	Entries[0] = Entry{ Path: map[string]string{ "left":  leftroot, "right": rightroot } }
+
+	runParallel(len(*unionset), func(i int) {
+		Entries[i+1] = getEntry(leftroot, rightroot, (*unionset)[i])
+	})
+
+	*content = append(*content, Entries...)
+
+	if Arg_TimeTolerance == "auto" {
+		TimeTolerance = max(getTimeGranularity(content, "left"), getTimeGranularity(content, "right"))
+		updateTimeDiffs(content)
+	}
 
-		NewEntry := Entry {
+	updateContentComparison(content)
 
-			NormPath  : NormPath,
-			Name      : Name,
-			IsDir     : IsDir,
-			IsDotfile : IsDotfile,
-			IsDiff    : (LeftChecksum != RightChecksum),
+}// >>>
 
-			Path     : map[string]string        { "left": LeftPath     , "right": RightPath      },
-			Size     : map[string]int64         { "left": LeftSize     , "right": RightSize      },
-			ModTime  : map[string]time.Time     { "left": LeftModTime  , "right": RightModTime   },
-			Checksum : map[string]string        { "left": LeftChecksum , "right": RightChecksum  },
-			IsMissing: map[string]bool          { "left": LeftIsMissing, "right": RightIsMissing },
-			IsOrphan : map[string]bool          { "left": LeftIsOrphan , "right": RightIsOrphan  },
-			SizeDiff : map[string]SizeDiffState { "left": LeftSizeState, "right": RightSizeState },
-			TimeDiff : map[string]TimeDiffState { "left": LeftTimeState, "right": RightTimeState }}
+func runParallel(count int, job func(i int)) {// <<<
+	// runs job for 0 <= i < count on Arg_Jobs workers
+	var Indices   = make(chan int)
+	var WaitGroup   sync.WaitGroup
 
-			*content  = append(*content, NewEntry)
+	Workers := Arg_Jobs
+	if Workers < 1 {
+		Workers = runtime.NumCPU()
+	}
+
+	for w:=0; w < Workers; w++ {
+		WaitGroup.Add(1)
+		go func() {
+			defer WaitGroup.Done()
+			for i := range Indices {
+				job(i)
+			}
+		}()
 	}
 
+	for i:=0; i < count; i++ {
+		Indices <- i
+	}
+	close(Indices)
+	WaitGroup.Wait()
+}// >>>
+
+func needsChecksum() bool {// <<<
+	// without any comparison option the filters and the exit code fall back to comparing the checksum
+	return Arg_Hash != "" || len(getActiveCriteria()) == 0
+}// >>>
+
+func updateContentComparison(content *[]Entry) {// <<<
+	if needsChecksum() {
+		updateChecksums(content)
+	}
+	if Arg_Bytes {
+		updateByteComparison(content)
+	}
+}// >>>
+
+func updateChecksums(content *[]Entry) {// <<<
+	// files of different size are already marked as IsDiff,
+	// they are only read to show their checksums with --info like updateByteComparison() does
+	runParallel(len(*content)-1, func(i int) {
+		E := &(*content)[i+1]
+
+		if E.IsDir || E.IsMissing["left"] || E.IsMissing["right"] || isLinkEntry(E) {
+			return
+		}
+
+		if E.Checksum["left"] != "" || (E.Size["left"] != E.Size["right"] && Arg_Info == false) {
+			return
+		}
+
+		LeftChecksum , LErr := getPathChecksum((*content)[0].Path["left"] , E.NormPath, HashAlgorithm)
+		RightChecksum, RErr := getPathChecksum((*content)[0].Path["right"], E.NormPath, HashAlgorithm)
+
+		for _, Err := range []error{LErr, RErr} {
+			if Err != nil {
+				printError(fmt.Sprintf("could not hash '%s': %s", E.NormPath, Err))
+				HasWalkErrors.Store(true)
+			}
+		}
+
+		// each entry has its own maps, so no locking is needed,
+		// a file that can't be read is never the same as the other one
+		E.Checksum["left"]  = LeftChecksum
+		E.Checksum["right"] = RightChecksum
+		E.IsDiff = (E.Size["left"] != E.Size["right"] || LErr != nil || RErr != nil || LeftChecksum != RightChecksum)
+	})
 }// >>>
 
 func decorateText(entry *Entry, side string) string {// <<<
+	Text, Style, Info := getDecoration(entry, side, Arg_Info)
+	return Style.Render(Text) + Info
+}// >>>
+
+func getDecoration(entry *Entry, side string, info bool) (string, lipgloss.Style, string) {// <<<
+	// returns the text of a node, its style and the info that follows it,
+	// info tells if the details of a difference are wanted like with --info
 
 	if (*entry).IsMissing[side] {
-		return StyleMissing.Render(strings.Repeat("░", len((*entry).Name)))
+		return strings.Repeat("░", len((*entry).Name)), StyleMissing, ""
+	}
+
+	if Arg_Base != "" {
+		Style, Info := getMergeDecoration(entry, side, info)
+		return (*entry).Name, Style, Info
+	}
+
+	if (*entry).IsLink[side] {
+		Style, Info := getLinkDecoration(entry, side)
+		return (*entry).Name, Style, Info
 	}
 
 	var Style lipgloss.Style = lipgloss.NewStyle()
 	var Info string = ""
 
-	if (*entry).IsOrphan[side] {
+	if (*entry).IsOrphan[side] && (*entry).MovePath != "" {
+		Style = StyleMoved
+		if side == "left" {
+			Info = " (moved to " + (*entry).MovePath
+		} else {
+			Info = " (moved from " + (*entry).MovePath
+		}
+		if (*entry).Similarity < 100 {
+			Info = Info + ", " + strconv.Itoa((*entry).Similarity) + "%"
+		}
+		Info = Info + ")"
+
+	} else if (*entry).IsOrphan[side] {
 		Style = StyleOrphan
 
 	} else {
 
-		if Arg_Size {
+		if len(getActiveCriteria()) > 1 {
+			if isSameEntry(entry) == false {
+				Style = StyleDiff
+				if info {
+					Info = " (" + strings.Join(getCriteriaInfo(entry, side), ", ") + ")"
+				}
+			}
+
+		} else if Arg_Size {
 			State := (*entry).SizeDiff[side]
 			Style = SizeStyles[State]
 
-			if Arg_Info && State != SameSize {
+			if info && State != SameSize {
 				Info = " (" + strconv.FormatInt(int64((*entry).Size[side]), 10) + " bytes)"
 			}
 
@@ -371,21 +725,57 @@ func decorateText(entry *Entry, side string) string {// <<<
 			State := (*entry).TimeDiff[side]
 			Style = TimeStyles[State]
 
-			if Arg_Info && State != SameTime {
+			if info && State != SameTime {
 				Info = " (" + (*entry).ModTime[side].Format(time.RFC3339) + ")"
 			}
 
-		} else if Arg_CRC32 {
+		} else if Arg_Mode {
+			if isSameMode(entry) == false {
+				Style = StyleDiff
+				if info {
+					Info = " (" + getModeBits((*entry).Mode[side]).String() + ")"
+				}
+			}
+
+		} else if Arg_Owner {
+			if isSameOwner(entry) == false {
+				Style = StyleDiff
+				if info {
+					Info = " (" + strconv.Itoa((*entry).Uid[side]) + ":" + strconv.Itoa((*entry).Gid[side]) + ")"
+				}
+			}
+
+		} else if Arg_Bytes {
+			if (*entry).IsDiff {
+				Style = StyleDiff
+				if info {
+					if (*entry).DiffOffset >= 0 {
+						Info = " (differs at byte " + strconv.FormatInt((*entry).DiffOffset, 10) + ")"
+					} else if (*entry).Size["left"] != (*entry).Size["right"] {
+						Info = " (size differs)"
+					} else {
+						Info = " (not readable)"
+					}
+				}
+			}
+
+		} else if Arg_Hash != "" {
 			if (*entry).IsDiff {
 				Style = StyleDiff
-				if Arg_Info {
-					Info = " (" + (*entry).Checksum[side] + ")"
+				if info {
+					if (*entry).Checksum[side] != "" {
+						Info = " (" + (*entry).Checksum[side] + ")"
+					} else if (*entry).Size["left"] != (*entry).Size["right"] { // not hashed since the size already differs
+						Info = " (size differs)"
+					} else {
+						Info = " (not readable)"
+					}
 				}
 			}
 		}
 	}
 
-	return Style.Render((*entry).Name) + Info
+	return (*entry).Name, Style, Info
 }// >>>
 
 func convertSliceToTree(content *[]Entry, side string) *tree.Tree { // <<<
@@ -497,22 +887,7 @@ func filterTrees(leftNode *tree.Node, rightNode *tree.Node) {// <<<
 		// Only check diff/same if the file isn't already hidden
 		if !shouldHide {
 			// 1. Determine the "isSame" status based on the active comparison mode
-			var isSame bool = false // Default to "different"
-
-			if Arg_Size {
-				// It's "same" if BOTH sides show SameSize.
-				// (Note: For orphans, one side won't be SameSize, so this is safe)
-				isSame = (E.SizeDiff["left"] == SameSize && E.SizeDiff["right"] == SameSize)
-
-			} else if Arg_Time {
-				// It's "same" if BOTH sides show SameTime.
-				isSame = (E.TimeDiff["left"] == SameTime && E.TimeDiff["right"] == SameTime)
-
-			} else {
-				// Default to CRC32 comparison (or if no mode is selected)
-				// You can just use E.IsDiff here.
-				isSame = !E.IsDiff 
-			}
+			var isSame bool = isSameEntry(E)
 
 			// 2. Apply the filter logic
 			if Arg_Diff && isSame {
@@ -602,12 +977,7 @@ func shortenPath(left_path string, right_path string, max_width int) (string, st
 	return LeftPath,RightPath
 }// >>>
 
-func printSideBySide(contents *[]Entry) {// <<<
-
-	var LeftTree  = convertSliceToTree(contents, "left")
-	var RightTree = convertSliceToTree(contents, "right")
-	var Output string
-
+func getRootDisplay(contents *[]Entry, column_width int) (string, string) {// <<<
 
 	LeftRoot  := (*contents)[0].Path["left"]
 	RightRoot := (*contents)[0].Path["right"]
@@ -622,17 +992,28 @@ func printSideBySide(contents *[]Entry) {// <<<
 	}
 
 	// if Arg_ShortenRoot {
-		var ColumnWidth int = 0
-		TermWidth, _, Err := term.GetSize(0)
-		if Err == nil {
-			ColumnWidth = (TermWidth-RightSideOffset)/2
-		}
-
-		// if (len(LeftRootDisplay) > ColumnWidth) || (len(RightRootDisplay) > ColumnWidth) { // only shorten if necessary
-			LeftRootDisplay, RightRootDisplay = shortenPath(LeftRootDisplay, RightRootDisplay, ColumnWidth)
+		// if (len(LeftRootDisplay) > column_width) || (len(RightRootDisplay) > column_width) { // only shorten if necessary
+			LeftRootDisplay, RightRootDisplay = shortenPath(LeftRootDisplay, RightRootDisplay, column_width)
 		// }
 	// }
 
+	return LeftRootDisplay, RightRootDisplay
+}// >>>
+
+func printSideBySide(contents *[]Entry) {// <<<
+
+	var LeftTree  = convertSliceToTree(contents, "left")
+	var RightTree = convertSliceToTree(contents, "right")
+	var Output string
+
+	var ColumnWidth int = 0
+	TermWidth, _, Err := term.GetSize(0)
+	if Err == nil {
+		ColumnWidth = (TermWidth-RightSideOffset)/2
+	}
+
+	LeftRootDisplay, RightRootDisplay := getRootDisplay(contents, ColumnWidth)
+
 	// if LeftRoot != LeftRootDisplay {
 	LeftTree.Node.SetText(StyleRoot.Render(LeftRootDisplay))
 	// }
@@ -646,10 +1027,72 @@ func printSideBySide(contents *[]Entry) {// <<<
 		LeftTree, RightTree = RightTree, LeftTree
 	}
 
-	Output = lipgloss.JoinHorizontal(lipgloss.Top, strings.Join(LeftTree.RenderTree(), "\n"), strings.Join(RightTree.SetRenderOffset(RightSideOffset).RenderTree(), "\n"))
+	LeftLines := LeftTree.RenderTree()
+	Output = lipgloss.JoinHorizontal(lipgloss.Top, strings.Join(LeftLines, "\n"), strings.Join(RightTree.SetRenderOffset(RightSideOffset).RenderTree(), "\n"))
+
+	if Arg_Content != "" {
+		Output = strings.Join(addContentDiffs(contents, strings.Split(Output, "\n"), LeftLines, LeftTree.GetVisibleNodes()), "\n")
+	}
 	fmt.Println(Output)
 }// >>>
 
+func getModeBits(mode fs.FileMode) fs.FileMode {// <<<
+	// the file type is already compared by IsDir
+	return mode & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+}// >>>
+
+func isSameMode(E *Entry) bool {// <<<
+	return E.IsMissing["left"] == E.IsMissing["right"] && getModeBits(E.Mode["left"]) == getModeBits(E.Mode["right"])
+}// >>>
+
+func isSameOwner(E *Entry) bool {// <<<
+	return E.IsMissing["left"] == E.IsMissing["right"] && E.Uid["left"] == E.Uid["right"] && E.Gid["left"] == E.Gid["right"]
+}// >>>
+
+func isLinkEntry(E *Entry) bool {// <<<
+	return E.IsLink["left"] || E.IsLink["right"]
+}// >>>
+
+func getLinkDecoration(entry *Entry, side string) (lipgloss.Style, string) {// <<<
+	var Style lipgloss.Style = lipgloss.NewStyle()
+
+	if (*entry).IsOrphan[side] {
+		Style = StyleOrphan
+	} else if (*entry).IsDiff {
+		Style = StyleDiff
+	}
+
+	return Style, " -> " + (*entry).LinkTarget[side]
+}// >>>
+
+func isSameEntry(E *Entry) bool {// <<<
+	// a file is the "same" depending on the active comparison mode
+	// (Note: For orphans, one side won't be SameSize, so this is safe)
+	if Arg_Base != "" {
+		return E.MergeState == Unchanged
+	} else if isLinkEntry(E) { // links are compared by their target in every mode
+		return !E.IsDiff
+	}
+
+	// with several criteria an entry differs as soon as one of them differs
+	for _, c := range getActiveCriteria() {
+		if isSameByCriterion(E, c) == false {
+			return false
+		}
+	}
+
+	// Default to checksum comparison (or if no mode is selected)
+	return len(getActiveCriteria()) > 0 || !E.IsDiff
+}// >>>
+
+func isDifferentEntry(E *Entry) bool {// <<<
+	// orphans aside, folders only differ by their mode and owner
+	if E.IsDir {
+		return (Arg_Mode || Arg_Owner) && !isSameEntry(E)
+	}
+	return !isSameEntry(E)
+}// >>>
+
 func shouldHideEntry(E *Entry) bool {// <<<
 // THIS FUNCTION WAS GENERATED USING AI BASED ON THE FILTERTREES() FUNCTION.
 // THE CODE SEEMS TO MAKE SENSE AND SEEMS TO WORK.
@@ -679,14 +1122,7 @@ func shouldHideEntry(E *Entry) bool {// <<<
 		}
 
 		// --- 3. Diff/Same Logic ---
-		var isSame bool = false
-		if Arg_Size {
-			isSame = (E.SizeDiff["left"] == SameSize && E.SizeDiff["right"] == SameSize)
-		} else if Arg_Time {
-			isSame = (E.TimeDiff["left"] == SameTime && E.TimeDiff["right"] == SameTime)
-		} else {
-			isSame = !E.IsDiff
-		}
+		var isSame bool = isSameEntry(E)
 
 		if Arg_Diff && isSame {
 			return true
@@ -708,9 +1144,56 @@ func printPlain(contents *[]Entry, QuoteChar string) {// <<<
 
 	for i:=1; i < len(*contents); i++ {
       if !shouldHideEntry(&(*contents)[i]) {
+         if (*contents)[i].MovePath != "" { // a moved file is printed once with its old and new path
+            if (*contents)[i].IsOrphan[Left] {
+               fmt.Printf("%s%s%s %s%s%s\n", QuoteChar, (*contents)[i].Path[Left], QuoteChar, QuoteChar, (*contents)[0].Path[Right] + (*contents)[i].MovePath, QuoteChar)
+            }
+            continue
+         }
+         if Arg_Base != "" { // the order matches "git merge-file current base other"
+            fmt.Printf("%s %s%s%s ", MergeStateNames[(*contents)[i].MergeState], QuoteChar, (*contents)[i].Path["left"], QuoteChar)
+            fmt.Printf("%s%s%s %s%s%s\n", QuoteChar, (*contents)[i].Path["base"], QuoteChar, QuoteChar, (*contents)[i].Path["right"], QuoteChar)
+            continue
+         }
          fmt.Printf("%s%s%s %s%s%s\n", QuoteChar, (*contents)[i].Path[Left], QuoteChar, QuoteChar, (*contents)[i].Path[Right], QuoteChar)
       }
 	}
 }// >>>
 
+func hasDifferences(contents *[]Entry) bool {// <<<
+	// judged like the printed output, hidden entries don't count
+	for i:=1; i < len(*contents); i++ {
+		E := &(*contents)[i]
+
+		if shouldHideEntry(E) {
+			continue
+		}
+
+		if E.IsDir {
+			// the children of an orphaned folder are orphans themselves,
+			// so only an empty orphaned folder makes a difference on its own
+			if !Arg_NoEmpty && (E.IsOrphan["left"] || E.IsOrphan["right"]) {
+				return true
+			}
+			if isDifferentEntry(E) {
+				return true
+			}
+		} else if E.IsOrphan["left"] || E.IsOrphan["right"] || isDifferentEntry(E) {
+			return true
+		}
+	}
+
+	return false
+}// >>>
+
+func getExitCode(contents *[]Entry) int {// <<<
+	if HasWalkErrors.Load() {
+		return INTERNAL
+	}
+	if hasDifferences(contents) {
+		return DIFFERENT
+	}
+	return OK
+}// >>>
+
 // vim: fdm=marker fmr=<<<,>>>
@@ -0,0 +1,181 @@
+package main
+
+// imports <<<
+import (
+	"io"
+	"os/exec"
+	"fmt"
+	"time"
+	"bytes"
+	"io/fs"
+	"strconv"
+	"strings"
+) // >>>
+
+// GitSide struct <<<
+type GitSide struct {
+	Manifest // the tree as listed by git ls-tree, the checksums are the blob ids
+
+	Ref    string
+	Subdir string
+}
+// >>>
+
+func parseGitSide(arg string) (string, string, bool) {// <<<
+	// splits git:REF[:subdir] into ref and subdir
+	if strings.HasPrefix(arg, "git:") == false {
+		return "", "", false
+	}
+
+	Ref, Subdir, _ := strings.Cut(strings.TrimPrefix(arg, "git:"), ":")
+	return Ref, strings.Trim(Subdir, "/"), Ref != ""
+}// >>>
+
+func runGit(args ...string) ([]byte, error) {// <<<
+	var Stderr bytes.Buffer
+
+	Cmd := exec.Command("git", args...)
+	Cmd.Stderr = &Stderr
+
+	Output, Err := Cmd.Output()
+	if Err != nil && Stderr.Len() > 0 {
+		return nil, fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(Stderr.String()))
+	}
+	return Output, Err
+}// >>>
+
+func getGitMode(mode string) fs.FileMode {// <<<
+	switch mode {
+	case "040000", "160000": // submodules are shown as empty folders
+		return fs.ModeDir | 0755
+	case "100755":
+		return 0755
+	case "120000":
+		return fs.ModeSymlink | 0777
+	}
+	return 0644
+}// >>>
+
+func loadGitSide(arg string) (*GitSide, error) {// <<<
+	// reads the tree from the object database of the repository in the working directory,
+	// nothing is checked out
+	Ref, Subdir, _ := parseGitSide(arg)
+	Result := GitSide{
+		Manifest : Manifest{Root: arg, Hash: "git"},
+		Ref      : Ref,
+		Subdir   : Subdir,
+	}
+
+	TreeIsh := Ref
+	if Subdir != "" {
+		TreeIsh = Ref + ":" + Subdir
+	}
+
+	// a path after the colon can not be combined with ^{tree}
+	if Type, Err := runGit("cat-file", "-t", TreeIsh); Err != nil || strings.TrimSpace(string(Type)) == "blob" {
+		return nil, fmt.Errorf("'%s' is no tree in the git repository", arg)
+	}
+
+	if Format, Err := runGit("rev-parse", "--show-object-format"); Err == nil && strings.TrimSpace(string(Format)) == "sha256" {
+		GitObjectFormat = "sha256"
+	}
+
+	// git keeps no modification times, all files get the time of the commit
+	var ModTime time.Time
+	if Output, Err := runGit("log", "-1", "--format=%ct", Ref, "--"); Err == nil {
+		if Seconds, Err := strconv.ParseInt(strings.TrimSpace(string(Output)), 10, 64); Err == nil {
+			ModTime = time.Unix(Seconds, 0)
+		}
+	}
+
+	Output, Err := runGit("ls-tree", "-r", "-t", "-l", "-z", "--full-tree", TreeIsh)
+	if Err != nil {
+		return nil, Err
+	}
+
+	// every record looks like "<mode> <type> <object> <size>\t<path>"
+	for _, Record := range strings.Split(strings.TrimSuffix(string(Output), "\x00"), "\x00") {
+		Info, FPath, Found := strings.Cut(Record, "\t")
+		Fields := strings.Fields(Info)
+		if Found == false || len(Fields) != 4 {
+			continue
+		}
+
+		E := ManifestEntry{
+			Path     : FPath,
+			ModTime  : ModTime,
+			Mode     : getGitMode(Fields[0]),
+			Checksum : Fields[2],
+		}
+
+		if E.Mode.IsDir() {
+			E.Path     = FPath + "/"
+			E.Checksum = ""
+		} else {
+			E.Size, _ = strconv.ParseInt(Fields[3], 10, 64)
+		}
+
+		if E.Mode & fs.ModeSymlink != 0 {
+			// the target of a link is stored as its content
+			Target, Err := runGit("cat-file", "blob", E.Checksum)
+			if Err != nil {
+				return nil, Err
+			}
+			E.Target = string(Target)
+		}
+
+		Result.Entries = append(Result.Entries, E)
+	}
+
+	// ls-tree sorts by name without the slash of folders
+	sortManifestEntries(Result.Entries)
+
+	Result.index = make(map[string]*ManifestEntry)
+	for i := range Result.Entries {
+		Result.index[Result.Entries[i].Path] = &Result.Entries[i]
+	}
+
+	return &Result, nil
+}// >>>
+
+func (self *GitSide) Checksum(normpath string, algo string) (string, error) {// <<<
+	// blob ids are known without reading, other algorithms read the blob from the repository
+	E, Exists := self.index[self.resolve(normpath)]
+	if Exists == false {
+		return "", &fs.PathError{Op: "checksum", Path: normpath, Err: fs.ErrNotExist}
+	}
+	if E.IsDir() || algo == "git" {
+		return E.Checksum, nil
+	}
+
+	Cmd := exec.Command("git", "cat-file", "blob", E.Checksum)
+	Reader, Err := Cmd.StdoutPipe()
+	if Err != nil {
+		return "", Err
+	}
+	if Err := Cmd.Start(); Err != nil {
+		return "", Err
+	}
+
+	Checksum, Err := getChecksumReader(Reader, algo)
+	if WaitErr := Cmd.Wait(); Err == nil {
+		Err = WaitErr
+	}
+	return Checksum, Err
+}// >>>
+
+func (self *GitSide) Open(normpath string) (io.ReadCloser, error) {// <<<
+	// the blob is read into memory, content diffs read the whole file anyway
+	E, Exists := self.index[self.resolve(normpath)]
+	if Exists == false || E.IsDir() {
+		return nil, &fs.PathError{Op: "open", Path: normpath, Err: fs.ErrNotExist}
+	}
+
+	Content, Err := runGit("cat-file", "blob", E.Checksum)
+	if Err != nil {
+		return nil, Err
+	}
+	return io.NopCloser(bytes.NewReader(Content)), nil
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
@@ -0,0 +1,230 @@
+package main
+
+// imports <<<
+import (
+	"os"
+	"fmt"
+	"regexp"
+	"path"
+	"strings"
+	"path/filepath"
+) // >>>
+
+// IgnoreRule struct <<<
+type IgnoreRule struct {
+	Base    string // folder of the ignore file relative to the root, empty or ending with a slash
+	Regex   *regexp.Regexp
+	Negate  bool
+	DirOnly bool
+}
+
+type IgnoreRules []IgnoreRule
+// >>>
+
+// Variables <<<
+var (
+	// patterns given with --ignore-file, they win over all .gitignore files like "git ls-files --exclude" does
+	IgnoreFileRules IgnoreRules
+)
+// >>>
+
+func convertIgnorePattern(pattern string) string {// <<<
+	// translates the glob part of a gitignore pattern into a regular expression
+	var Result strings.Builder
+
+	for i:=0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			Result.WriteString("(.*/)?")
+			i = i + 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			Result.WriteString(".*")
+			i = i + 1
+		case pattern[i] == '*':
+			Result.WriteString("[^/]*")
+		case pattern[i] == '?':
+			Result.WriteString("[^/]")
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			Result.WriteString(regexp.QuoteMeta(pattern[i+1:i+2]))
+			i = i + 1
+		case pattern[i] == '[':
+			End := strings.Index(pattern[i+1:], "]")
+			if End < 0 {
+				Result.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			Class := pattern[i+1:i+1+End]
+			if strings.HasPrefix(Class, "!") {
+				Class = "^" + Class[1:]
+			}
+			Result.WriteString("[" + Class + "]")
+			i = i + 1 + End
+		default:
+			Result.WriteString(regexp.QuoteMeta(pattern[i:i+1]))
+		}
+	}
+
+	return Result.String()
+}// >>>
+
+func parseIgnoreLines(content string, base string) (IgnoreRules, error) {// <<<
+	var Rules IgnoreRules
+
+	for _, Line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		// trailing spaces are ignored unless they are escaped
+		for strings.HasSuffix(Line, " ") && strings.HasSuffix(Line, "\\ ") == false {
+			Line = Line[:len(Line)-1]
+		}
+
+		if Line == "" || strings.HasPrefix(Line, "#") {
+			continue
+		}
+
+		Rule := IgnoreRule{Base: base}
+
+		if strings.HasPrefix(Line, "!") {
+			Rule.Negate = true
+			Line = Line[1:]
+		} else if strings.HasPrefix(Line, "\\!") || strings.HasPrefix(Line, "\\#") {
+			Line = Line[1:]
+		}
+
+		if strings.HasSuffix(Line, "/") {
+			Rule.DirOnly = true
+			Line = strings.TrimSuffix(Line, "/")
+		}
+
+		// a pattern with a slash is relative to the folder of the ignore file,
+		// otherwise it matches the name at any depth
+		var Prefix string = "(.*/)?"
+		if strings.Contains(Line, "/") {
+			Prefix = ""
+			Line = strings.TrimPrefix(Line, "/")
+		}
+
+		Regex, Err := regexp.Compile("^" + Prefix + convertIgnorePattern(Line) + "$")
+		if Err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern '%s': %s", Line, Err)
+		}
+		Rule.Regex = Regex
+
+		Rules = append(Rules, Rule)
+	}
+
+	return Rules, nil
+}// >>>
+
+func readIgnoreFile(fpath string, base string) (IgnoreRules, error) {// <<<
+	Content, Err := os.ReadFile(fpath)
+	if Err != nil {
+		return nil, Err
+	}
+	return parseIgnoreLines(string(Content), base)
+}// >>>
+
+func (self IgnoreRules) Match(fpath string, isdir bool) (bool, bool) {// <<<
+	// returns if a rule matched and if the path is ignored,
+	// fpath is relative to the root and has no trailing slash, the last matching rule wins
+	var Matched bool = false
+	var Ignored bool = false
+
+	for _, r := range self {
+		if strings.HasPrefix(fpath, r.Base) == false || (r.DirOnly && isdir == false) {
+			continue
+		}
+		if r.Regex.MatchString(strings.TrimPrefix(fpath, r.Base)) {
+			Matched = true
+			Ignored = !r.Negate
+		}
+	}
+
+	return Matched, Ignored
+}// >>>
+
+func isIgnoredPath(rules IgnoreRules, fpath string, isdir bool) bool {// <<<
+	if Matched, Ignored := IgnoreFileRules.Match(fpath, isdir); Matched {
+		return Ignored
+	}
+	_, Ignored := rules.Match(fpath, isdir)
+	return Ignored
+}// >>>
+
+func getGlobalIgnoreFile() string {// <<<
+	// the default location of core.excludesFile
+	if ConfigHome := os.Getenv("XDG_CONFIG_HOME"); ConfigHome != "" {
+		return filepath.Join(ConfigHome, "git", "ignore")
+	}
+	if Home, Err := os.UserHomeDir(); Err == nil {
+		return filepath.Join(Home, ".config", "git", "ignore")
+	}
+	return ""
+}// >>>
+
+func getRootIgnoreRules(root string) IgnoreRules {// <<<
+	// rules that apply to the whole side, deeper .gitignore files are added by walkDir()
+	var Rules IgnoreRules
+
+	if Arg_GitIgnore == false {
+		return nil
+	}
+
+	for _, f := range []string{getGlobalIgnoreFile(), root + ".git/info/exclude"} {
+		if f == "" {
+			continue
+		}
+		if FileRules, Err := readIgnoreFile(f, ""); Err == nil {
+			Rules = append(Rules, FileRules...)
+		}
+	}
+
+	return Rules
+}// >>>
+
+func getDirIgnoreRules(root string, dir string, rules IgnoreRules) IgnoreRules {// <<<
+	// adds the rules of the .gitignore in dir, a new slice keeps the rules of sibling folders apart
+	if Arg_GitIgnore == false {
+		return rules
+	}
+
+	var Base string = ""
+	if dir != "" {
+		Base = dir + "/"
+	}
+
+	FileRules, Err := readIgnoreFile(root + Base + ".gitignore", Base)
+	if Err != nil {
+		if os.IsNotExist(Err) == false {
+			printError(Err.Error())
+		}
+		return rules
+	}
+
+	return append(append(IgnoreRules{}, rules...), FileRules...)
+}// >>>
+
+func isGitFolder(fpath string, isdir bool) bool {// <<<
+	// git never looks into its own folder
+	return Arg_GitIgnore && isdir && path.Base(fpath) == ".git"
+}// >>>
+
+func isIgnoredBySide(sides []IgnoreRules, fpath string, isdir bool) bool {// <<<
+	for _, Rules := range sides {
+		if isIgnoredPath(Rules, fpath, isdir) || isGitFolder(fpath, isdir) {
+			return true
+		}
+	}
+	return false
+}// >>>
+
+func isIgnoredInUnion(fpath string, sides []IgnoreRules) bool {// <<<
+	// a path of the union is ignored if the rules of any side ignore it or one of its folders,
+	// also when the path only exists on another side
+	for i:=0; i < len(fpath); i++ {
+		if fpath[i] == '/' && isIgnoredBySide(sides, fpath[:i], true) {
+			return true
+		}
+	}
+	return isDir(fpath) == false && isIgnoredBySide(sides, fpath, false)
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
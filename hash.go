@@ -0,0 +1,181 @@
+package main
+
+// imports <<<
+import (
+	"os"
+	"io"
+	"bytes"
+	"fmt"
+	"hash"
+	"strings"
+	"hash/crc32"
+	"encoding/hex"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"github.com/cespare/xxhash/v2"
+	"github.com/zeebo/blake3"
+) // >>>
+
+// Variables <<<
+var (
+	// the algorithm that fills Entry.Checksum, set via --hash or --crc32
+	HashAlgorithm string = "crc32"
+
+	HashAlgorithms = []string{"crc32", "md5", "sha1", "sha256", "xxh64", "blake3", "git"}
+
+	// the hash of git object ids, sha1 unless a git side uses a sha256 repository
+	GitObjectFormat string = "sha1"
+
+	CompareBlockSize int = 64 * 1024
+)
+// >>>
+
+func isHashAlgorithm(algo string) bool {// <<<
+	for _, a := range HashAlgorithms {
+		if a == algo {
+			return true
+		}
+	}
+	return false
+}// >>>
+
+func sumReader(h hash.Hash, reader io.Reader) (string, error) {// <<<
+	if _, Err := io.Copy(h, reader); Err != nil {
+		return "", Err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}// >>>
+
+func getChecksumReader(reader io.Reader, algo string) (string, error) {// <<<
+	// io.Copy keeps the data a reader returns together with io.EOF, archive readers do that
+	switch algo {
+	case "crc32":
+		return sumReader(crc32.NewIEEE(), reader)
+	case "md5":
+		return sumReader(md5.New(), reader)
+	case "sha1":
+		return sumReader(sha1.New(), reader)
+	case "sha256":
+		return sumReader(sha256.New(), reader)
+	case "xxh64":
+		return sumReader(xxhash.New(), reader)
+	case "blake3":
+		return sumReader(blake3.New(), reader)
+	case "git":
+		// the size is part of the object id, so the content is read first
+		Content, Err := io.ReadAll(reader)
+		if Err != nil {
+			return "", Err
+		}
+		return getGitBlobHash(bytes.NewReader(Content), int64(len(Content)))
+	}
+	return "", fmt.Errorf("unknown hash algorithm '%s', use one of %s", algo, strings.Join(HashAlgorithms, ", "))
+}// >>>
+
+func getChecksum(fpath string, algo string) (string, error) {// <<<
+	File, Err := os.Open(fpath)
+	if Err != nil {
+		return "", Err
+	}
+	defer File.Close()
+
+	if algo == "git" {
+		FileInfo, Err := File.Stat()
+		if Err != nil {
+			return "", Err
+		}
+		return getGitBlobHash(File, FileInfo.Size())
+	}
+
+	return getChecksumReader(File, algo)
+}// >>>
+
+func getGitBlobHash(reader io.Reader, size int64) (string, error) {// <<<
+	// the id git gives the content as blob, like "git hash-object" prints it
+	var h hash.Hash = sha1.New()
+	if GitObjectFormat == "sha256" {
+		h = sha256.New()
+	}
+	fmt.Fprintf(h, "blob %d\x00", size)
+	return sumReader(h, reader)
+}// >>>
+
+func compareBytes(leftroot string, rightroot string, normpath string) (int64, error) {// <<<
+	// returns the offset of the first differing byte or -1 if both files are equal,
+	// reading stops at the first block that differs
+	LeftFile, Err := openPath(leftroot, normpath)
+	if Err != nil {
+		return -1, Err
+	}
+	defer LeftFile.Close()
+
+	RightFile, Err := openPath(rightroot, normpath)
+	if Err != nil {
+		return -1, Err
+	}
+	defer RightFile.Close()
+
+	LeftBuffer  := make([]byte, CompareBlockSize)
+	RightBuffer := make([]byte, CompareBlockSize)
+	var Offset int64 = 0
+
+	for {
+		LeftCount , LErr := io.ReadFull(LeftFile , LeftBuffer)
+		RightCount, RErr := io.ReadFull(RightFile, RightBuffer)
+
+		if LErr != nil && LErr != io.EOF && LErr != io.ErrUnexpectedEOF {
+			return -1, LErr
+		}
+		if RErr != nil && RErr != io.EOF && RErr != io.ErrUnexpectedEOF {
+			return -1, RErr
+		}
+
+		if bytes.Equal(LeftBuffer[:LeftCount], RightBuffer[:RightCount]) == false {
+			for i:=0; i < min(LeftCount, RightCount); i++ {
+				if LeftBuffer[i] != RightBuffer[i] {
+					return Offset + int64(i), nil
+				}
+			}
+			// one file is a prefix of the other one
+			return Offset + int64(min(LeftCount, RightCount)), nil
+		}
+
+		if LeftCount < CompareBlockSize { // both reached the end
+			return -1, nil
+		}
+
+		Offset = Offset + int64(LeftCount)
+	}
+}// >>>
+
+func updateByteComparison(content *[]Entry) {// <<<
+	// files of different size are already marked as IsDiff,
+	// they are only read to find the offset for --info
+	runParallel(len(*content)-1, func(i int) {
+		E := &(*content)[i+1]
+
+		if E.IsDir || E.IsMissing["left"] || E.IsMissing["right"] || isLinkEntry(E) {
+			return
+		}
+
+		if E.Size["left"] != E.Size["right"] && Arg_Info == false {
+			return
+		}
+
+		Offset, Err := compareBytes((*content)[0].Path["left"], (*content)[0].Path["right"], E.NormPath)
+		if Err != nil {
+			// a file that can't be read is never the same as the other one
+			printError(fmt.Sprintf("could not compare '%s': %s", E.NormPath, Err))
+			HasWalkErrors.Store(true)
+			E.DiffOffset = -1
+			E.IsDiff     = true
+			return
+		}
+
+		E.DiffOffset = Offset
+		E.IsDiff     = (Offset >= 0)
+	})
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
@@ -0,0 +1,215 @@
+package main
+
+// imports <<<
+import (
+	"os"
+	"fmt"
+	"time"
+	"strconv"
+	"strings"
+	"html/template"
+	"github.com/charmbracelet/lipgloss"
+	"diffee/tree"
+) // >>>
+
+// HTML report <<<
+type HTMLCell struct {
+	Text  string
+	Style template.CSS
+	Info  string
+	Title string
+}
+
+type HTMLRow struct {
+	IsDir    bool
+	Depth    int
+	Cells    []HTMLCell
+	Children []HTMLRow
+}
+
+type HTMLReport struct {
+	Version string
+	Created string
+	Compare string
+	Roots   []HTMLCell
+	Summary [][2]string
+	Rows    []HTMLRow
+}
+// >>>
+
+// Variables <<<
+var (
+	// the 16 colors of the xterm palette, the terminal ones can't be known
+	ANSIColors = []string{
+		"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+		"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+	}
+
+	HTMLTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>diffee{{range .Roots}} {{.Text}}{{end}}</title>
+<style>
+body    { background: #1e1e1e; color: #d0d0d0; font-family: monospace; font-size: 14px; margin: 1em 2em; }
+table   { border-collapse: collapse; margin-bottom: 1.5em; }
+td      { padding: 0 1.5em 0 0; }
+.row    { display: grid; grid-template-columns: repeat({{len .Roots}}, minmax(0, 1fr)); column-gap: 2em; }
+.row:hover { background: #2a2a2a; }
+.cell   { white-space: pre; overflow: hidden; text-overflow: ellipsis; }
+.cell::before { content: "  "; }
+.info   { color: #8a8a8a; }
+summary { list-style: none; cursor: pointer; }
+summary::-webkit-details-marker { display: none; }
+summary .cell::before { content: "▸ "; color: #8a8a8a; }
+details[open] > summary .cell::before { content: "▾ "; }
+.roots  { border-bottom: 1px solid #444; margin-bottom: 0.3em; }
+</style>
+</head>
+<body>
+<table>
+<tr><td>created</td><td>{{.Created}} by diffee {{.Version}}</td></tr>
+<tr><td>compare</td><td>{{.Compare}}</td></tr>
+{{range .Summary}}<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>
+{{end}}</table>
+<div class="row roots">{{range .Roots}}<div class="cell" style="{{.Style}}">{{.Text}}</div>{{end}}</div>
+{{template "rows" .Rows}}
+</body>
+</html>
+{{define "cells"}}{{$Depth := .Depth}}{{range .Cells}}<div class="cell" title="{{.Title}}" style="padding-left: {{$Depth}}em"><span style="{{.Style}}">{{.Text}}</span><span class="info">{{.Info}}</span></div>{{end}}{{end}}
+{{define "rows"}}{{range .}}{{if .IsDir}}<details open><summary class="row">{{template "cells" .}}</summary>
+{{template "rows" .Children}}</details>
+{{else}}<div class="row">{{template "cells" .}}</div>
+{{end}}{{end}}{{end}}`))
+)
+// >>>
+
+func getCSSColor(color lipgloss.TerminalColor) string {// <<<
+	// ANSI color numbers are translated to the xterm palette, hex colors are kept
+	Color, IsColor := color.(lipgloss.Color)
+	if IsColor == false || Color == "" {
+		return ""
+	}
+	if strings.HasPrefix(string(Color), "#") {
+		return string(Color)
+	}
+
+	n, Err := strconv.Atoi(string(Color))
+	switch {
+	case Err != nil || n < 0 || n > 255:
+		return ""
+	case n < 16:
+		return ANSIColors[n]
+	case n < 232: // 6x6x6 color cube
+		Levels := []int{0, 95, 135, 175, 215, 255}
+		n = n - 16
+		return fmt.Sprintf("#%02x%02x%02x", Levels[n/36], Levels[n/6%6], Levels[n%6])
+	}
+	Gray := 8 + (n-232)*10
+	return fmt.Sprintf("#%02x%02x%02x", Gray, Gray, Gray)
+}// >>>
+
+func getCSSStyle(style lipgloss.Style) template.CSS {// <<<
+	var Result []string
+
+	if Color := getCSSColor(style.GetForeground()); Color != "" {
+		Result = append(Result, "color: " + Color)
+	}
+	if Color := getCSSColor(style.GetBackground()); Color != "" {
+		Result = append(Result, "background: " + Color)
+	}
+	if style.GetBold() {
+		Result = append(Result, "font-weight: bold")
+	}
+	if style.GetItalic() {
+		Result = append(Result, "font-style: italic")
+	}
+	if style.GetUnderline() {
+		Result = append(Result, "text-decoration: underline")
+	}
+
+	return template.CSS(strings.Join(Result, "; "))
+}// >>>
+
+func getHTMLCell(E *Entry, side string) HTMLCell {// <<<
+	// the tooltip always shows the details, the --info text is shown after the name as in the terminal
+	Text, Style, Info := getDecoration(E, side, Arg_Info)
+	_, _, Details := getDecoration(E, side, true)
+
+	var Title []string = []string{E.Path[side]}
+	if E.IsMissing[side] {
+		Title = []string{"missing"}
+	} else if E.IsDir == false && E.IsLink[side] == false {
+		Title = append(Title, "size: " + strconv.FormatInt(E.Size[side], 10) + " bytes")
+		Title = append(Title, "modified: " + E.ModTime[side].Format(time.RFC3339))
+	}
+	if Details = strings.TrimSpace(Details); Details != "" {
+		Title = append(Title, Details)
+	}
+
+	return HTMLCell{
+		Text  : Text,
+		Style : getCSSStyle(Style),
+		Info  : Info,
+		Title : strings.Join(Title, "\n"),
+	}
+}// >>>
+
+func convertNodesToHTML(nodes []*tree.Node, sides []string, depth int) []HTMLRow {// <<<
+	// all trees are built from the same slice, so the nodes of the first tree stand for all of them
+	var Result []HTMLRow
+
+	for _, n := range nodes {
+		E, IsEntry := n.GetData().(*Entry)
+		if n.IsHidden() || IsEntry == false {
+			continue
+		}
+
+		Row := HTMLRow{IsDir: E.IsDir, Depth: depth}
+		for _, Side := range sides {
+			Row.Cells = append(Row.Cells, getHTMLCell(E, Side))
+		}
+		if E.IsDir {
+			Row.Children = convertNodesToHTML(n.GetChildren(), sides, depth+1)
+		}
+
+		Result = append(Result, Row)
+	}
+
+	return Result
+}// >>>
+
+func printHTML(contents *[]Entry) error {// <<<
+	var Sides = []string{"left", "right"}
+
+	if Arg_Base != "" {
+		Sides = []string{"left", "base", "right"}
+	} else if Arg_Swap {
+		Sides = []string{"right", "left"}
+	}
+
+	// the trees are only built to apply the same filters as printSideBySide()
+	LeftTree  := convertSliceToTree(contents, "left")
+	RightTree := convertSliceToTree(contents, "right")
+	filterTrees(&LeftTree.Node, &RightTree.Node)
+
+	Report := HTMLReport{
+		Version : Version,
+		Created : time.Now().Format(time.RFC3339),
+		Compare : getCompareName(),
+		Summary : getStatLines(getStats(contents, Sides[0], Sides[len(Sides)-1]), Sides[0], Sides[len(Sides)-1]),
+		Rows    : convertNodesToHTML(LeftTree.Node.GetChildren(), Sides, 0),
+	}
+
+	// the aliases replace the roots like in the terminal, the base has none
+	LeftRoot, RightRoot := getRootDisplay(contents, 0)
+	RootDisplay := map[string]string{"left": LeftRoot, "base": (*contents)[0].Path["base"], "right": RightRoot}
+
+	for _, Side := range Sides {
+		Report.Roots = append(Report.Roots, HTMLCell{Text: RootDisplay[Side], Style: getCSSStyle(StyleRoot)})
+	}
+
+	return HTMLTemplate.Execute(os.Stdout, Report)
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
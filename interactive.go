@@ -0,0 +1,315 @@
+package main
+
+// imports <<<
+import (
+	"fmt"
+	"strings"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	tea "github.com/charmbracelet/bubbletea"
+	"diffee/tree"
+) // >>>
+
+// InteractiveModel struct <<<
+type InteractiveModel struct {
+	Contents   *[]Entry
+	LeftTree   *tree.Tree
+	RightTree  *tree.Tree
+	Nodes      []*tree.Node    // visible nodes, same order as the rendered lines
+	Collapsed  map[string]bool // NormPath of collapsed folders, survives rebuilding the trees
+	Cursor     int
+	Offset     int
+	Width      int
+	Height     int
+	Message    string          // shown in the status bar until the next key press
+}
+// >>>
+
+// Variables <<<
+var (
+	StyleStatusBar = lipgloss.NewStyle().Reverse(true)
+	CursorSign     = "▸ "
+)
+// >>>
+
+func runInteractive(contents *[]Entry) error {// <<<
+	Model := &InteractiveModel{Contents: contents, Collapsed: make(map[string]bool)}
+	Model.buildTrees()
+
+	_, Err := tea.NewProgram(Model, tea.WithAltScreen()).Run()
+	return Err
+}// >>>
+
+func getNodePath(node *tree.Node) string {// <<<
+	// the root node carries no Entry, it is identified by the empty string
+	if E, ok := node.GetData().(*Entry); ok {
+		return E.NormPath
+	}
+	return ""
+}// >>>
+
+func (self *InteractiveModel) buildTrees() {// <<<
+
+	var SelectedPath string = ""
+	if self.Cursor < len(self.Nodes) {
+		SelectedPath = getNodePath(self.Nodes[self.Cursor])
+	}
+
+	self.LeftTree  = convertSliceToTree(self.Contents, "left")
+	self.RightTree = convertSliceToTree(self.Contents, "right")
+
+	// shortenPath needs some room for the ellipsis, 0 disables shortening
+	RootWidth := self.getColumnWidth()-len([]rune(CursorSign))
+	if RootWidth < 4 {
+		RootWidth = 0
+	}
+
+	LeftRootDisplay, RightRootDisplay := getRootDisplay(self.Contents, RootWidth)
+	self.LeftTree.Node.SetText(StyleRoot.Render(LeftRootDisplay))
+	self.RightTree.Node.SetText(StyleRoot.Render(RightRootDisplay))
+
+	filterTrees(&self.LeftTree.Node, &self.RightTree.Node)
+
+	self.LeftTree.SetRenderStyle(tree.RenderFolderStyle)
+	self.RightTree.SetRenderStyle(tree.RenderFolderStyle)
+
+	// both trees are built from the same slice, so they iterate in the same order
+	RightNodes := self.RightTree.Iterate(nil)
+	for LeftNode := range self.LeftTree.Iterate(nil) {
+		RightNode := <-RightNodes
+		if self.Collapsed[getNodePath(LeftNode)] {
+			LeftNode.HideChildren(true)
+			RightNode.HideChildren(true)
+		}
+	}
+
+	self.Nodes  = self.LeftTree.GetVisibleNodes()
+	self.Cursor = 0
+	for i, n := range self.Nodes {
+		if getNodePath(n) == SelectedPath {
+			self.Cursor = i
+			break
+		}
+	}
+	self.scrollToCursor()
+}// >>>
+
+func (self *InteractiveModel) getColumnWidth() int {// <<<
+	return (self.Width-RightSideOffset)/2
+}// >>>
+
+func (self *InteractiveModel) getViewHeight() int {// <<<
+	// the last line is reserved for the status bar
+	if self.Height < 2 {
+		return 1
+	}
+	return self.Height-1
+}// >>>
+
+func (self *InteractiveModel) scrollToCursor() {// <<<
+	if self.Cursor < self.Offset {
+		self.Offset = self.Cursor
+	} else if self.Cursor >= self.Offset+self.getViewHeight() {
+		self.Offset = self.Cursor - self.getViewHeight() + 1
+	}
+}// >>>
+
+func (self *InteractiveModel) moveCursor(delta int) {// <<<
+	self.Cursor = self.Cursor + delta
+	if self.Cursor >= len(self.Nodes) {
+		self.Cursor = len(self.Nodes)-1
+	}
+	if self.Cursor < 0 {
+		self.Cursor = 0
+	}
+	self.scrollToCursor()
+}// >>>
+
+func (self *InteractiveModel) setCollapsed(collapsed bool) bool {// <<<
+	// returns false if the node under the cursor is not a folder or already in the requested state
+	Node := self.Nodes[self.Cursor]
+	if Node.GetParent() == nil || Node.CountChildren(false) == 0 || Node.ChildrenHidden() == collapsed {
+		return false
+	}
+
+	NormPath := getNodePath(Node)
+	if collapsed {
+		self.Collapsed[NormPath] = true
+	} else {
+		delete(self.Collapsed, NormPath)
+	}
+	self.buildTrees()
+	return true
+}// >>>
+
+func getCompareModeError(mode string) string {// <<<
+	// the same limits checkManifestArgs() puts on the command line
+	for Root, s := range Sides {
+		m, IsManifest := s.(*Manifest)
+		if IsManifest == false {
+			continue
+		}
+		if mode == "bytes" {
+			return fmt.Sprintf("bytes can not be compared, '%s' is a manifest", strings.TrimSuffix(Root, "/"))
+		}
+		if mode == "checksum" && m.Hash != HashAlgorithm {
+			return fmt.Sprintf("manifest '%s' contains %s checksums, not %s", strings.TrimSuffix(Root, "/"), m.Hash, HashAlgorithm)
+		}
+	}
+	return ""
+}// >>>
+
+func (self *InteractiveModel) setCompareMode(mode string) {// <<<
+	if Message := getCompareModeError(mode); Message != "" {
+		self.Message = Message
+		return
+	}
+
+	// pressing the key of the active mode again turns the comparison off
+	IsActive := (mode == "size" && Arg_Size) || (mode == "time" && Arg_Time) ||
+	            (mode == "checksum" && Arg_Hash != "") || (mode == "bytes" && Arg_Bytes) ||
+	            (mode == "mode" && Arg_Mode) || (mode == "owner" && Arg_Owner)
+
+	Arg_Size, Arg_Time, Arg_Hash, Arg_Bytes, Arg_Mode, Arg_Owner = false, false, "", false, false, false
+
+	if IsActive == false {
+		switch mode {
+		case "size":     Arg_Size  = true
+		case "time":     Arg_Time  = true
+		case "checksum": Arg_Hash  = HashAlgorithm
+		case "bytes":    Arg_Bytes = true
+		case "mode":     Arg_Mode  = true
+		case "owner":    Arg_Owner = true
+		}
+	}
+
+	updateContentComparison(self.Contents) // checksums that are already known are not computed again
+	self.buildTrees()
+}// >>>
+
+func (self *InteractiveModel) Init() tea.Cmd {// <<<
+	return nil
+}// >>>
+
+func (self *InteractiveModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {// <<<
+
+	switch Msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		self.Width  = Msg.Width
+		self.Height = Msg.Height
+		self.buildTrees()
+
+	case tea.KeyMsg:
+		self.Message = ""
+		switch Msg.String() {
+		case "q", "esc", "ctrl+c":
+			return self, tea.Quit
+		case "up", "k":
+			self.moveCursor(-1)
+		case "down", "j":
+			self.moveCursor(1)
+		case "pgup", "ctrl+b":
+			self.moveCursor(-self.getViewHeight())
+		case "pgdown", "ctrl+f", " ":
+			self.moveCursor(self.getViewHeight())
+		case "home", "g":
+			self.moveCursor(-len(self.Nodes))
+		case "end", "G":
+			self.moveCursor(len(self.Nodes))
+		case "enter", "tab":
+			if self.setCollapsed(true) == false {
+				self.setCollapsed(false)
+			}
+		case "right", "l":
+			self.setCollapsed(false)
+		case "left", "h":
+			if self.setCollapsed(true) == false {
+				// jump to the parent folder instead
+				Parent := self.Nodes[self.Cursor].GetParent()
+				for i, n := range self.Nodes {
+					if n == Parent {
+						self.Cursor = i
+						self.scrollToCursor()
+						break
+					}
+				}
+			}
+		case "s":
+			self.setCompareMode("size")
+		case "t":
+			self.setCompareMode("time")
+		case "c":
+			self.setCompareMode("checksum")
+		case "b":
+			self.setCompareMode("bytes")
+		case "m":
+			self.setCompareMode("mode")
+		case "o":
+			self.setCompareMode("owner")
+		case "i":
+			Arg_Info = !Arg_Info
+			self.buildTrees()
+		case "x":
+			Arg_Swap = !Arg_Swap
+		}
+	}
+
+	return self, nil
+}// >>>
+
+func (self *InteractiveModel) renderColumn(lines []string, width int) []string {// <<<
+	var Column []string
+
+	for i:=self.Offset; i < len(lines) && i < self.Offset+self.getViewHeight(); i++ {
+		Sign := strings.Repeat(" ", len([]rune(CursorSign)))
+		if i == self.Cursor {
+			Sign = CursorSign
+		}
+		Line := ansi.Truncate(Sign + lines[i], width, "…")
+		Column = append(Column, Line + strings.Repeat(" ", max(0, width-lipgloss.Width(Line))))
+	}
+
+	return Column
+}// >>>
+
+func (self *InteractiveModel) renderStatusBar() string {// <<<
+	Status := fmt.Sprintf(" %d/%d  compare: %s  info: %t │ s size  t time  c checksum  b bytes  m mode  o owner  i info  x swap  ⏎ fold  q quit",
+		self.Cursor+1, len(self.Nodes), getCompareName(), Arg_Info)
+
+	if self.Message != "" {
+		Status = fmt.Sprintf(" %d/%d  %s", self.Cursor+1, len(self.Nodes), self.Message)
+	}
+
+	return StyleStatusBar.Render(ansi.Truncate(Status + strings.Repeat(" ", max(0, self.Width-len([]rune(Status)))), self.Width, "…"))
+}// >>>
+
+func (self *InteractiveModel) View() string {// <<<
+
+	if self.Width == 0 {
+		return ""
+	}
+
+	var LeftTree  = self.LeftTree
+	var RightTree = self.RightTree
+
+	if Arg_Swap {
+		LeftTree, RightTree = RightTree, LeftTree
+	}
+
+	ColumnWidth := self.getColumnWidth()
+	LeftLines   := self.renderColumn(LeftTree.RenderTree() , ColumnWidth)
+	RightLines  := self.renderColumn(RightTree.RenderTree(), ColumnWidth)
+
+	var Lines []string
+	for i := range LeftLines {
+		Lines = append(Lines, LeftLines[i] + strings.Repeat(" ", RightSideOffset) + RightLines[i])
+	}
+	for len(Lines) < self.getViewHeight() {
+		Lines = append(Lines, "")
+	}
+
+	return strings.Join(Lines, "\n") + "\n" + self.renderStatusBar()
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
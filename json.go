@@ -0,0 +1,234 @@
+package main
+
+// imports <<<
+import (
+	"os"
+	"io/fs"
+	"time"
+	"strings"
+	"encoding/json"
+) // >>>
+
+// JSON schema <<<
+// bump JSONSchemaVersion whenever a field is removed or changes its meaning,
+// adding new fields is not considered a breaking change
+const JSONSchemaVersion int = 1
+
+type JSONHeader struct {
+	Kind          string `json:"kind,omitempty"`
+	SchemaVersion int    `json:"schema_version"`
+	Version       string `json:"diffee_version"`
+	Compare       string `json:"compare"`
+	Left          string `json:"left_root"`
+	Right         string `json:"right_root"`
+	Base          string `json:"base_root,omitempty"`
+}
+
+type JSONDocument struct {
+	JSONHeader
+	Entries []JSONEntry `json:"entries"`
+}
+
+type JSONSide struct {
+	Path     string     `json:"path"`
+	Missing  bool       `json:"missing"`
+	Orphan   bool       `json:"orphan"`
+	Size     *int64     `json:"size,omitempty"`
+	ModTime  *time.Time `json:"mtime,omitempty"`
+	Checksum string     `json:"checksum,omitempty"`
+	Mode     string     `json:"mode,omitempty"`
+	Uid      *int       `json:"uid,omitempty"`
+	Gid      *int       `json:"gid,omitempty"`
+	Target   string     `json:"link_target,omitempty"`
+	SizeDiff string     `json:"size_diff,omitempty"`
+	TimeDiff string     `json:"time_diff,omitempty"`
+}
+
+type JSONEntry struct {
+	Kind     string   `json:"kind"`
+	Path     string   `json:"path"`
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Dotfile  bool     `json:"dotfile"`
+	Status   string   `json:"status"`
+	Merge    string   `json:"merge,omitempty"`
+	Offset   *int64   `json:"diff_offset,omitempty"`
+	MovePath string   `json:"move_path,omitempty"`
+	Similarity int    `json:"similarity,omitempty"`
+	Left     JSONSide `json:"left"`
+	Right    JSONSide `json:"right"`
+	Base     *JSONSide `json:"base,omitempty"`
+}
+// >>>
+
+// Variables <<<
+var (
+	SizeDiffNames = map[SizeDiffState]string{
+		SameSize: "same",
+		Bigger:   "bigger",
+		Smaller:  "smaller",
+	}
+
+	TimeDiffNames = map[TimeDiffState]string{
+		SameTime: "same",
+		Newer:    "newer",
+		Older:    "older",
+	}
+)
+// >>>
+
+func getCompareName() string {// <<<
+	// several criteria are joined with a plus, e.g. size+time
+	Criteria := getActiveCriteria()
+
+	if len(Criteria) == 0 {
+		return "none"
+	}
+
+	for i := range Criteria {
+		if Criteria[i] == "hash" {
+			Criteria[i] = Arg_Hash
+		}
+	}
+	return strings.Join(Criteria, "+")
+}// >>>
+
+func getEntryStatus(E *Entry, left string, right string) string {// <<<
+	if E.IsOrphan[left] {
+		return "left-orphan"
+	} else if E.IsOrphan[right] {
+		return "right-orphan"
+	} else if isDifferentEntry(E) {
+		return "different"
+	}
+	return "same"
+}// >>>
+
+func convertSideToJSON(E *Entry, side string) JSONSide {// <<<
+	Side := JSONSide{
+		Path    : E.Path[side],
+		Missing : E.IsMissing[side],
+		Orphan  : E.IsOrphan[side],
+	}
+
+	if E.IsMissing[side] {
+		return Side
+	}
+
+	// the type bits tell folders and links apart, the other ones like sockets are of no interest
+	Side.Mode = (getModeBits(E.Mode[side]) | E.Mode[side] & (fs.ModeDir | fs.ModeSymlink)).String()
+	if E.Uid[side] >= 0 {
+		Uid, Gid := E.Uid[side], E.Gid[side]
+		Side.Uid, Side.Gid = &Uid, &Gid
+	}
+
+	if E.IsLink[side] {
+		Side.Target = E.LinkTarget[side]
+	}
+
+	// folders carry no file information
+	if E.IsDir {
+		return Side
+	}
+
+	Size    := E.Size[side]
+	ModTime := E.ModTime[side]
+
+	Side.Size     = &Size
+	Side.ModTime  = &ModTime
+	Side.Checksum = E.Checksum[side]
+
+	// orphans have nothing to be compared with, the base is not compared directly
+	if E.IsOrphan[side] == false && side != "base" {
+		Side.SizeDiff = SizeDiffNames[E.SizeDiff[side]]
+		Side.TimeDiff = TimeDiffNames[E.TimeDiff[side]]
+	}
+
+	return Side
+}// >>>
+
+func convertEntryToJSON(E *Entry, left string, right string) JSONEntry {// <<<
+	var Type string = "file"
+	if E.IsDir {
+		Type = "dir"
+	} else if isLinkEntry(E) {
+		Type = "link"
+	}
+
+	var Offset *int64 = nil
+	if E.DiffOffset >= 0 {
+		Offset = &E.DiffOffset
+	}
+
+	Result := JSONEntry{
+		Kind    : "entry",
+		Path    : E.NormPath,
+		Name    : E.Name,
+		Type    : Type,
+		Dotfile : E.IsDotfile,
+		Status  : getEntryStatus(E, left, right),
+		Offset  : Offset,
+		MovePath: E.MovePath,
+		Left    : convertSideToJSON(E, left),
+		Right   : convertSideToJSON(E, right),
+	}
+
+	if E.MovePath != "" {
+		Result.Similarity = E.Similarity
+	}
+
+	if Arg_Base != "" {
+		Base := convertSideToJSON(E, "base")
+		Result.Base  = &Base
+		Result.Merge = MergeStateNames[E.MergeState]
+	}
+
+	return Result
+}// >>>
+
+func printJSON(contents *[]Entry, streaming bool) error {// <<<
+	var Left  string = "left"
+	var Right string = "right"
+
+	if Arg_Swap {
+		Left, Right = Right, Left
+	}
+
+	Header := JSONHeader{
+		SchemaVersion : JSONSchemaVersion,
+		Version       : Version,
+		Compare       : getCompareName(),
+		Left          : (*contents)[0].Path[Left],
+		Right         : (*contents)[0].Path[Right],
+		Base          : (*contents)[0].Path["base"],
+	}
+
+	Encoder := json.NewEncoder(os.Stdout)
+
+	if streaming { // one JSON object per line, starting with the header
+		Header.Kind = "header"
+		if Err := Encoder.Encode(Header); Err != nil {
+			return Err
+		}
+		for i:=1; i < len(*contents); i++ {
+			if !shouldHideEntry(&(*contents)[i]) {
+				if Err := Encoder.Encode(convertEntryToJSON(&(*contents)[i], Left, Right)); Err != nil {
+					return Err
+				}
+			}
+		}
+		return nil
+	}
+
+	Document := JSONDocument{JSONHeader: Header, Entries: []JSONEntry{}}
+	for i:=1; i < len(*contents); i++ {
+		if !shouldHideEntry(&(*contents)[i]) {
+			Document.Entries = append(Document.Entries, convertEntryToJSON(&(*contents)[i], Left, Right))
+		}
+	}
+
+	Encoder.SetIndent("", "  ")
+	return Encoder.Encode(Document)
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
@@ -5,7 +5,10 @@ import ( // <<<
 	"fmt"
 	"path"
 	"regexp"
+	"strings"
+	"time"
 	"github.com/spf13/cobra"
+	"github.com/bmatcuk/doublestar/v4"
 ) // >>>
 
 // global variables, constants and types <<<
@@ -13,6 +16,7 @@ const Version string = "0.3.0"
 
 const (
 	OK int = iota
+	DIFFERENT
 	INTERNAL
 	CMDLINE
 	TOO_MANY_ARGS
@@ -23,21 +27,33 @@ const (
 var QuoteChar string = ""
 
 type RegExes []*regexp.Regexp
+type Globs   []string
 var (
 	Arg_Version      bool
 	Arg_Help         bool
 	Arg_Plain        bool
+	Arg_Interactive  bool
 	Arg_SingleQuotes bool
 	Arg_DoubleQuotes bool
 	Arg_All          bool
 	Arg_Size         bool
 	Arg_Time         bool
 	Arg_CRC32        bool
+	Arg_Hash         string
+	Arg_Bytes        bool
+	Arg_Mode         bool
+	Arg_Owner        bool
+	Arg_Symlinks     string
+	Arg_TimeTolerance string
+	Arg_GitIgnore    bool
+	Arg_IgnoreFiles  []string
 	Arg_Info         bool
 	Arg_Swap         bool
 	// Arg_ShortenRoot  bool
 	Arg_Depth        int
+	Arg_Jobs         int
 	Arg_NoColor      bool
+	Arg_Theme        string
 	Arg_Orphans      bool
 	Arg_NoOrphans    bool
 	Arg_LeftOrphans  bool
@@ -50,8 +66,23 @@ var (
 	Arg_Bash         bool
 	Arg_Exclude      RegExes
 	Arg_Include      RegExes
+	Arg_ExcludeGlob  Globs
+	Arg_IncludeGlob  Globs
 	Arg_LeftAlias    string
 	Arg_RightAlias   string
+	Arg_Format       string
+	Arg_Content      string
+	Arg_Output       string
+	Arg_Base         string
+	Arg_StripComponents int
+	Arg_Stat         bool
+	Arg_Layout       string
+	Arg_StatOnly     bool
+	Arg_SyncTo       string
+	Arg_SyncDelete   bool
+	Arg_DryRun       bool
+	Arg_Moves        bool
+	Arg_Similarity   int
 )
 // >>>
 
@@ -61,13 +92,126 @@ func (n *RegExes) String() string {
 }
 
 func (n *RegExes) Set(value string) error {
-    *n = append(*n, regexp.MustCompile(value))
+    RegEx, Err := regexp.Compile(value)
+    if Err != nil {
+        return fmt.Errorf("invalid regex '%s': %s", value, Err)
+    }
+    *n = append(*n, RegEx)
     return nil
 }
 
 func (n *RegExes) Type() string {
     return "regex"
 }
+
+func (n *Globs) String() string {
+    return fmt.Sprintf("%s", *n)
+}
+
+func (n *Globs) Set(value string) error {
+    if doublestar.ValidatePattern(value) == false {
+        return fmt.Errorf("invalid glob '%s'", value)
+    }
+    *n = append(*n, value)
+    return nil
+}
+
+func (n *Globs) Type() string {
+    return "glob"
+}
+
+func checkCompareArgs() {
+	// all other comparison options can be combined, see isSameEntry()
+	if Arg_CRC32 && Arg_Hash != "" && Arg_Hash != "crc32" {
+		printError("--crc32 and --hash can not be used together, use only one")
+		os.Exit(EXCLUSIVE_OPTS)
+	}
+
+	if Arg_TimeTolerance != "auto" {
+		Tolerance, Err := time.ParseDuration(Arg_TimeTolerance)
+		if Err != nil || Tolerance < 0 {
+			printError(fmt.Sprintf("invalid time tolerance '%s', use a duration like 2s or auto", Arg_TimeTolerance))
+			os.Exit(CMDLINE)
+		}
+		TimeTolerance = Tolerance
+	}
+
+	checkHashArgs()
+}
+
+func checkHashArgs() {
+	// --crc32 is the short form of --hash=crc32
+	if Arg_CRC32 {
+		Arg_Hash = "crc32"
+	}
+
+	if Arg_Hash != "" {
+		if isHashAlgorithm(Arg_Hash) == false {
+			printError(fmt.Sprintf("unknown hash algorithm '%s', use one of %s", Arg_Hash, strings.Join(HashAlgorithms, ", ")))
+			os.Exit(CMDLINE)
+		}
+		HashAlgorithm = Arg_Hash
+	}
+
+	if Arg_Jobs < 0 {
+		printError("--jobs must not be negative")
+		os.Exit(CMDLINE)
+	}
+
+	for _, f := range Arg_IgnoreFiles {
+		Rules, Err := readIgnoreFile(f, "")
+		if Err != nil {
+			printError(fmt.Sprintf("could not read ignore file: %s", Err))
+			os.Exit(CMDLINE)
+		}
+		IgnoreFileRules = append(IgnoreFileRules, Rules...)
+	}
+
+	switch Arg_Symlinks {
+	case "follow", "compare-target", "ignore":
+	default:
+		printError(fmt.Sprintf("unknown symlink policy '%s', use follow, compare-target or ignore", Arg_Symlinks))
+		os.Exit(CMDLINE)
+	}
+}
+
+func checkManifestArgs() {
+	// the checksums of a manifest can only be compared with checksums of the same algorithm
+	var ManifestHash string = ""
+	var HasManifest  bool   = false
+
+	// git sides know the blob ids of their files without reading them
+	for _, s := range Sides {
+		if _, IsGit := s.(*GitSide); IsGit && Arg_Hash == "" {
+			HashAlgorithm = "git"
+		}
+	}
+
+	for Root, s := range Sides {
+		m, IsManifest := s.(*Manifest)
+		if IsManifest == false {
+			continue
+		}
+		HasManifest = true
+
+		// m.Root is the folder the manifest was created from, the user knows the manifest by its file name
+		if Arg_Hash != "" && Arg_Hash != m.Hash {
+			printError(fmt.Sprintf("manifest '%s' contains %s checksums, use --hash=%s", strings.TrimSuffix(Root, "/"), m.Hash, m.Hash))
+			os.Exit(CMDLINE)
+		}
+		if ManifestHash != "" && ManifestHash != m.Hash {
+			printError("both manifests must use the same hash algorithm")
+			os.Exit(CMDLINE)
+		}
+		ManifestHash  = m.Hash
+		HashAlgorithm = m.Hash
+	}
+
+	if HasManifest && (Arg_Bytes || Arg_Content != "") {
+		printError("--bytes and --content need the file contents and can not be used with a manifest")
+		os.Exit(CMDLINE)
+	}
+}
 // >>>
 
 func main() {
@@ -75,7 +219,7 @@ func main() {
 	// variables <<<
 	var LeftDir               string
 	var RightDir              string
-	var XORDiffType           int = 0
+	var BaseDir               string
 	var XOROrphanType         int = 0
 	var UnionSetOfDirContents []string
 	var DirContentInformation []Entry
@@ -85,6 +229,15 @@ func main() {
 	rootCmd := &cobra.Command{
 		Use:   "diffee [left_dir] <right_dir>",
 		Short: "Diff directories",
+		Args:  cobra.ArbitraryArgs,
+		// the config file is read by all commands, before their own checks,
+		// a broken config file must not keep --version, --bash or the help from working
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			if Arg_Version || Arg_Bash || Arg_Help || (cmd == cmd.Root() && len(args) == 0) {
+				return
+			}
+			loadConfig(cmd)
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 
 			// check cli args <<<
@@ -107,13 +260,7 @@ func main() {
 				os.Exit(TOO_MANY_ARGS)
 			}
 
-			if Arg_Size  { XORDiffType += 1 }
-			if Arg_Time  { XORDiffType += 1 }
-			if Arg_CRC32 { XORDiffType += 1 }
-			if XORDiffType > 1 {
-				printError("--size, --time and --crc32 are mutual exclusive, use only one")
-				os.Exit(EXCLUSIVE_OPTS)
-			}
+			checkCompareArgs()
 
 			if Arg_Orphans      { XOROrphanType += 1 }
 			if Arg_NoOrphans    { XOROrphanType += 1 }
@@ -134,6 +281,57 @@ func main() {
 				os.Exit(EXCLUSIVE_OPTS)
 			}
 
+			switch Arg_Format {
+			case "tree", "json", "ndjson", "html":
+			default:
+				printError(fmt.Sprintf("unknown format '%s', use tree, json, ndjson or html", Arg_Format))
+				os.Exit(CMDLINE)
+			}
+
+			if Arg_Format != "tree" && (Arg_Plain || Arg_Interactive) {
+				printError("--format can not be used together with --plain or --interactive")
+				os.Exit(EXCLUSIVE_OPTS)
+			}
+
+			if (Arg_Stat || Arg_StatOnly) && (Arg_Format != "tree" || Arg_Plain || Arg_Interactive) {
+				printError("--stat and --stat-only can not be used together with --format, --plain or --interactive")
+				os.Exit(EXCLUSIVE_OPTS)
+			}
+
+			switch Arg_Layout {
+			case "side-by-side", "unified":
+			default:
+				printError(fmt.Sprintf("unknown layout '%s', use side-by-side or unified", Arg_Layout))
+				os.Exit(CMDLINE)
+			}
+
+			if Arg_Layout == "unified" && (Arg_Format != "tree" || Arg_Plain || Arg_Interactive || Arg_Base != "" || Arg_Content != "") {
+				printError("--layout unified can not be used together with --format, --plain, --interactive, --base or --content")
+				os.Exit(EXCLUSIVE_OPTS)
+			}
+
+			switch Arg_Content {
+			case "", "unified", "side-by-side":
+			default:
+				printError(fmt.Sprintf("unknown content diff style '%s', use unified or side-by-side", Arg_Content))
+				os.Exit(CMDLINE)
+			}
+
+			if Arg_Content != "" && (Arg_Format != "tree" || Arg_Plain || Arg_Interactive) {
+				printError("--content can not be used together with --format, --plain or --interactive")
+				os.Exit(EXCLUSIVE_OPTS)
+			}
+
+			if Arg_Base != "" && (Arg_Content != "" || Arg_Interactive || Arg_Swap) {
+				printError("--base can not be used together with --content, --interactive or --swap")
+				os.Exit(EXCLUSIVE_OPTS)
+			}
+
+			if Arg_Similarity < 1 || Arg_Similarity > 100 {
+				printError("--similarity must be between 1 and 100")
+				os.Exit(CMDLINE)
+			}
+
 			if Arg_SingleQuotes && Arg_DoubleQuotes {
 				printError("--single-quotes and --double-quotes can not be used together, use only one")
 				os.Exit(EXCLUSIVE_OPTS)
@@ -159,25 +357,96 @@ func main() {
 				LeftDir = path.Clean(args[0]) + "/"
 				RightDir = path.Clean(args[1]) + "/"
 			}
+
+			var Dirs = []string{LeftDir, RightDir}
+			if Arg_Base != "" {
+				BaseDir = path.Clean(Arg_Base) + "/"
+				Dirs = append(Dirs, BaseDir)
+			}
 			// >>>
 
-			// check if dirs exists <<<
-			if isDirectory(LeftDir) == false {
-				printError(fmt.Sprintf("given path '%s' is not a directory", LeftDir))
-				os.Exit(NOT_A_DIR)
+			// check if dirs exists, a file is accepted if it is an archive or a manifest, git:REF is read from the repository <<<
+			if Arg_StripComponents < 0 {
+				printError("--strip-components can not be negative")
+				os.Exit(CMDLINE)
 			}
 
-			if isDirectory(RightDir) == false {
-				printError(fmt.Sprintf("given path '%s' is not a directory", RightDir))
-				os.Exit(NOT_A_DIR)
+			for _, Dir := range Dirs {
+				if isDirectory(Dir) {
+					continue
+				}
+
+				if _, _, IsGit := parseGitSide(path.Clean(Dir)); IsGit {
+					GitSide, Err := loadGitSide(path.Clean(Dir))
+					if Err != nil {
+						printError(Err.Error())
+						os.Exit(NOT_A_DIR)
+					}
+					Sides[Dir] = GitSide
+					continue
+				}
+
+				if isRegularFile(path.Clean(Dir)) == false {
+					printError(fmt.Sprintf("given path '%s' is not a directory", Dir))
+					os.Exit(NOT_A_DIR)
+				}
+
+				if getArchiveKind(path.Clean(Dir)) != "" {
+					Archive, Err := loadArchive(path.Clean(Dir))
+					if Err != nil {
+						printError(Err.Error())
+						os.Exit(NOT_A_DIR)
+					}
+					Sides[Dir] = Archive
+					continue
+				}
+
+				Manifest, Err := loadManifest(path.Clean(Dir))
+				if Err != nil {
+					printError(Err.Error())
+					os.Exit(NOT_A_DIR)
+				}
+				Sides[Dir] = Manifest
 			}
+
+			checkManifestArgs()
 			// >>>
 
 			// get dir contents <<<
-			getUnionSetOfDirContents(LeftDir, RightDir, &UnionSetOfDirContents)
+			getUnionSetOfDirContents(Dirs, &UnionSetOfDirContents)
 			getDirContentInformation(LeftDir, RightDir, &UnionSetOfDirContents, &DirContentInformation)
+			if Arg_Base != "" {
+				updateMergeStates(BaseDir, &DirContentInformation)
+			}
+			if Arg_Moves {
+				updateMoves(&DirContentInformation)
+			}
 			// >>>
 
+			// print json output <<<
+			if Arg_Format == "json" || Arg_Format == "ndjson" {
+				if Err := printJSON(&DirContentInformation, Arg_Format == "ndjson"); Err != nil {
+					printError(fmt.Sprintf("could not write json: %s", Err))
+					os.Exit(INTERNAL)
+				}
+				os.Exit(getExitCode(&DirContentInformation))
+			}// >>>
+
+			// print html report <<<
+			if Arg_Format == "html" {
+				if Err := printHTML(&DirContentInformation); Err != nil {
+					printError(fmt.Sprintf("could not write html: %s", Err))
+					os.Exit(INTERNAL)
+				}
+				os.Exit(getExitCode(&DirContentInformation))
+			}// >>>
+
+			// print statistics only <<<
+			if Arg_StatOnly {
+				printStats(&DirContentInformation)
+				os.Exit(getExitCode(&DirContentInformation))
+			}// >>>
+
 			// print plain output <<<
 			if Arg_Plain {
 				if Arg_SingleQuotes {
@@ -189,22 +458,139 @@ func main() {
 				}
 
 				printPlain(&DirContentInformation, QuoteChar)
-				os.Exit(OK)
+				os.Exit(getExitCode(&DirContentInformation))
 			}// >>>
 
 			// start interactive comparison <<<
-			// if Interactive {
-			// runInteractive(&DirContentInformation)
-			// os.Exit(OK)
-			// } // >>>
+			if Arg_Interactive {
+				if Err := runInteractive(&DirContentInformation); Err != nil {
+					printError(fmt.Sprintf("interactive mode failed: %s", Err))
+					os.Exit(INTERNAL)
+				}
+				os.Exit(getExitCode(&DirContentInformation))
+			} // >>>
+
+			// print three-way comparison <<<
+			if Arg_Base != "" {
+				printThreeWay(&DirContentInformation)
+				if Arg_Stat {
+					fmt.Println()
+					printStats(&DirContentInformation)
+				}
+				os.Exit(getExitCode(&DirContentInformation))
+			} // >>>
 
-			// print side by side comparison <<<
-			printSideBySide(&DirContentInformation)
-			os.Exit(OK)
+			// print side by side or unified comparison <<<
+			if Arg_Layout == "unified" {
+				printUnified(&DirContentInformation)
+			} else {
+				printSideBySide(&DirContentInformation)
+			}
+			if Arg_Stat {
+				fmt.Println()
+				printStats(&DirContentInformation)
+			}
+			os.Exit(getExitCode(&DirContentInformation))
 			// >>>
 
 		},
 	}
+
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot <dir>",
+		Short: "Save sizes, times, modes and checksums of a directory to a manifest that can be diffed later",
+		Run: func(cmd *cobra.Command, args []string) {
+
+			if len(args) == 0 {
+				cmd.Help()
+				return
+			}
+
+			if len(args) > 1 {
+				printError("too many arguments")
+				os.Exit(TOO_MANY_ARGS)
+			}
+
+			// CRC32 is too weak to be stored for later
+			HashAlgorithm = "sha256"
+			checkHashArgs()
+
+			Dir := path.Clean(args[0]) + "/"
+			if isDirectory(Dir) == false {
+				printError(fmt.Sprintf("given path '%s' is not a directory", Dir))
+				os.Exit(NOT_A_DIR)
+			}
+
+			Manifest, Err := createManifest(Dir)
+			if Err != nil {
+				printError(fmt.Sprintf("could not create manifest: %s", Err))
+				os.Exit(INTERNAL)
+			}
+
+			if Err := writeManifest(Manifest, Arg_Output); Err != nil {
+				printError(fmt.Sprintf("could not write manifest: %s", Err))
+				os.Exit(INTERNAL)
+			}
+			os.Exit(OK)
+		},
+	}
+
+	syncCmd := &cobra.Command{
+		Use:   "sync [left_dir] <right_dir> --to <side>",
+		Short: "Copy orphans and files that differ to one side, only prints what would be done unless --dry-run=false is given",
+		Run: func(cmd *cobra.Command, args []string) {
+
+			var LeftDir  string
+			var RightDir string
+			var From     string
+
+			if len(args) == 0 {
+				cmd.Help()
+				return
+			}
+
+			if len(args) > 2 {
+				printError("too many arguments")
+				os.Exit(TOO_MANY_ARGS)
+			}
+
+			switch Arg_SyncTo {
+			case "left" : From = "right"
+			case "right": From = "left"
+			default:
+				printError("--to must be left or right")
+				os.Exit(CMDLINE)
+			}
+
+			checkCompareArgs()
+
+			switch len(args) {
+			case 1:
+				LeftDir = "./"
+				RightDir = path.Clean(args[0]) + "/"
+			case 2:
+				LeftDir = path.Clean(args[0]) + "/"
+				RightDir = path.Clean(args[1]) + "/"
+			}
+
+			// manifests can not be synchronized
+			for _, Dir := range []string{LeftDir, RightDir} {
+				if isDirectory(Dir) == false {
+					printError(fmt.Sprintf("given path '%s' is not a directory", Dir))
+					os.Exit(NOT_A_DIR)
+				}
+			}
+
+			getUnionSetOfDirContents([]string{LeftDir, RightDir}, &UnionSetOfDirContents)
+			getDirContentInformation(LeftDir, RightDir, &UnionSetOfDirContents, &DirContentInformation)
+
+			os.Exit(runSync(getSyncSteps(&DirContentInformation, From, Arg_SyncTo), []string{LeftDir, RightDir}, Arg_DryRun))
+		},
+	}
+
+	rootCmd.AddCommand(snapshotCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
 	// >>>
 
 	// commandline parameter definition <<<
@@ -212,10 +598,18 @@ func main() {
 	rootCmd.Flags().BoolVarP(&Arg_Version      , "version"      , "v", false , "print version")
 	rootCmd.Flags().BoolVarP(&Arg_Bash         , "bash"         , "b", false , "generate bash-completion script")
 	// control input
-	rootCmd.Flags().BoolVarP(&Arg_All          , "all"          , "a", false , "don't ignore dotfiles")
-	rootCmd.Flags().IntVarP(&Arg_Depth         , "depth"        , "D", 0     , "limit depth, 0 is no limit and the default")
-	rootCmd.Flags().VarP(&Arg_Include          , "include"      , "I",         "include matching paths into diff, can be used multiple times, if --include and --exclude are used together then --include is applied first")
-	rootCmd.Flags().VarP(&Arg_Exclude          , "exclude"      , "E",         "exclude matching paths from diff, can be used multiple times, if --include and --exclude are used together then --include is applied first")
+	rootCmd.PersistentFlags().BoolVarP(&Arg_All          , "all"          , "a", false , "don't ignore dotfiles")
+	rootCmd.PersistentFlags().IntVarP(&Arg_Depth         , "depth"        , "D", 0     , "limit depth, 0 is no limit and the default")
+	rootCmd.PersistentFlags().IntVarP(&Arg_Jobs          , "jobs"         , "j", 0     , "number of parallel workers for reading directories and files, 0 uses one per CPU and is the default")
+	rootCmd.PersistentFlags().VarP(&Arg_Include          , "include"      , "I",         "include matching paths into diff, can be used multiple times, if --include and --exclude are used together then --include is applied first")
+	rootCmd.PersistentFlags().VarP(&Arg_IncludeGlob      , "include-glob" , "" ,         "include paths matching the glob, ** matches any number of folders, can be used multiple times, folders end with a slash")
+	rootCmd.PersistentFlags().VarP(&Arg_ExcludeGlob      , "exclude-glob" , "" ,         "exclude paths matching the glob, ** matches any number of folders, can be used multiple times, folders end with a slash")
+	rootCmd.PersistentFlags().BoolVarP(&Arg_GitIgnore    , "gitignore"    , "" , false , "skip paths ignored by .gitignore files, .git/info/exclude and the global git excludes file")
+	rootCmd.PersistentFlags().StringArrayVarP(&Arg_IgnoreFiles, "ignore-file", "" , nil  , "skip paths matching the gitignore patterns in the given file, can be used multiple times")
+	rootCmd.PersistentFlags().StringVarP(&Arg_Symlinks   , "symlinks"     , "" , "follow", "how symbolic links are handled, one of follow, compare-target or ignore")
+	rootCmd.Flags().StringVarP(&Arg_Base          , "base"         , "" , ""    , "common base folder for a three-way comparison of left and right")
+	rootCmd.Flags().IntVarP(&Arg_StripComponents  , "strip-components", "" , 0   , "remove this many leading folders from the paths inside an archive")
+	rootCmd.PersistentFlags().VarP(&Arg_Exclude          , "exclude"      , "E",         "exclude matching paths from diff, can be used multiple times, if --include and --exclude are used together then --include is applied first")
 	// control output
 	rootCmd.Flags().BoolVarP(&Arg_Diff         , "diff"         , "d", false , "show only files that differ")
 	rootCmd.Flags().BoolVarP(&Arg_Same         , "same"         , "m", false , "show only files that are the same")
@@ -227,19 +621,40 @@ func main() {
 	rootCmd.Flags().BoolVarP(&Arg_LeftOrphans  , "left-orphans" , "L", false , "show only left orphans")
 	rootCmd.Flags().BoolVarP(&Arg_RightOrphans , "right-orphans", "R", false , "show only right orphans")
 	rootCmd.Flags().BoolVarP(&Arg_Plain        , "plain"        , "p", false , "print differences in plain format, use --single-quotes/-q or --double-quotes/-Q to wrap in quotes, useful in combination with xargs")
+	rootCmd.Flags().BoolVarP(&Arg_Interactive  , "interactive"  , "i", false , "browse the comparison in an interactive two-pane tree view")
+	rootCmd.Flags().StringVarP(&Arg_Format     , "format"       , "" , "tree", "output format, one of tree, json, ndjson or html")
 	rootCmd.Flags().BoolVarP(&Arg_SingleQuotes , "single-quotes", "q", false , "wrap plain output in single quotes")
 	rootCmd.Flags().BoolVarP(&Arg_DoubleQuotes , "double-quotes", "Q", false , "wrap plain output in double quotes")
+	rootCmd.Flags().BoolVarP(&Arg_Stat         , "stat"         , "" , false , "print totals of files, folders, differences, orphans and size changes after the comparison")
+	rootCmd.Flags().BoolVarP(&Arg_StatOnly     , "stat-only"    , "" , false , "print only the totals of --stat")
+	rootCmd.Flags().StringVarP(&Arg_Content    , "content"      , "" , ""    , "print a line diff for each file that differs, either unified (default) or side-by-side")
+	rootCmd.Flags().Lookup("content").NoOptDefVal = "unified"
 	// control comparison
-	rootCmd.Flags().BoolVarP(&Arg_Size         , "size"         , "s", false , "compare file size")
-	rootCmd.Flags().BoolVarP(&Arg_Time         , "time"         , "t", false , "compare modification time")
-	rootCmd.Flags().BoolVarP(&Arg_CRC32        , "crc32"        , "c", false , "compare CRC32 checksum")
+	rootCmd.PersistentFlags().BoolVarP(&Arg_Size         , "size"         , "s", false , "compare file size")
+	rootCmd.PersistentFlags().BoolVarP(&Arg_Time         , "time"         , "t", false , "compare modification time")
+	rootCmd.PersistentFlags().StringVarP(&Arg_TimeTolerance, "time-tolerance", "" , "0s"  , "treat modification times as the same if they differ by at most this duration, e.g. 2s, or auto to detect the granularity of the file systems")
+	rootCmd.PersistentFlags().BoolVarP(&Arg_CRC32        , "crc32"        , "c", false , "compare CRC32 checksum, same as --hash=crc32")
+	rootCmd.PersistentFlags().BoolVarP(&Arg_Bytes        , "bytes"        , "B", false , "compare byte by byte, stops reading at the first difference")
+	rootCmd.PersistentFlags().StringVarP(&Arg_Hash       , "hash"         , "H", ""    , "compare checksum, one of crc32, md5, sha1, sha256, xxh64, blake3 or git")
+	rootCmd.PersistentFlags().BoolVarP(&Arg_Mode         , "mode"         , "" , false , "compare permission bits")
+	rootCmd.PersistentFlags().BoolVarP(&Arg_Owner        , "owner"        , "" , false , "compare owner and group id")
+	rootCmd.Flags().BoolVarP(&Arg_Moves        , "moves"        , "M", false , "show left and right orphans with the same content as moved files")
+	rootCmd.Flags().IntVarP(&Arg_Similarity    , "similarity"   , "" , 100   , "with --moves, also match text files with at least this percentage of equal lines")
 	// control display
+	rootCmd.Flags().StringVarP(&Arg_Layout     , "layout"       , "" , "side-by-side", "tree layout, side-by-side or unified for a single tree with the markers + - ~ =")
 	rootCmd.Flags().BoolVarP(&Arg_Swap         , "swap"         , "x", false , "swap sides")
 	rootCmd.Flags().BoolVarP(&Arg_Info         , "info"         , "n", false , "print file diff info")
 	rootCmd.Flags().BoolVarP(&Arg_NoColor      , "no-color"     , "C", false , "turn colored output off, overwrites NO_COLOR")
+	rootCmd.PersistentFlags().StringVarP(&Arg_Theme, "theme"        , "" , ""    , "color theme, default, light or a theme of the config file")
 	rootCmd.Flags().StringVarP(&Arg_LeftAlias  , "left-alias"   , "l", ""    , "display the given string as left root folder name")
 	rootCmd.Flags().StringVarP(&Arg_RightAlias , "right-alias"  , "r", ""    , "display the given string as right root folder name")
 	// rootCmd.Flags().BoolVarP(&Arg_ShortenRoot  , "shorten-root" , "S", false , "shorten the root path if possible")
+	// snapshot
+	snapshotCmd.Flags().StringVarP(&Arg_Output , "output"       , "o", ""    , "write the manifest to the given file instead of stdout")
+	// sync
+	syncCmd.Flags().StringVarP(&Arg_SyncTo     , "to"           , "" , ""    , "side that is changed, left or right")
+	syncCmd.Flags().BoolVarP(&Arg_SyncDelete   , "delete"       , "" , false , "also delete the orphans of the changed side")
+	syncCmd.Flags().BoolVarP(&Arg_DryRun       , "dry-run"      , "" , true  , "only print what would be done, use --dry-run=false to apply the changes")
 	// >>>
 
 
@@ -0,0 +1,267 @@
+package main
+
+// imports <<<
+import (
+	"os"
+	"io"
+	"fmt"
+	"sort"
+	"path"
+	"time"
+	"io/fs"
+	"strings"
+	"path/filepath"
+	"encoding/json"
+) // >>>
+
+// Manifest struct <<<
+// bump ManifestSchemaVersion whenever a field is removed or changes its meaning
+const ManifestSchemaVersion int = 1
+
+type ManifestEntry struct {
+	Path     string      `json:"path"` // NormPath, folders end with a slash
+	Size     int64       `json:"size"`
+	ModTime  time.Time   `json:"mtime"`
+	Mode     fs.FileMode `json:"mode"`
+	Checksum string      `json:"checksum,omitempty"`
+	Uid      *int        `json:"uid,omitempty"`
+	Gid      *int        `json:"gid,omitempty"`
+	Target   string      `json:"target,omitempty"` // only set for symlinks
+}
+
+type Manifest struct {
+	SchemaVersion int             `json:"schema_version"`
+	Version       string          `json:"diffee_version"`
+	Root          string          `json:"root"`
+	Created       time.Time       `json:"created"`
+	Hash          string          `json:"hash"`
+	Entries       []ManifestEntry `json:"entries"`
+
+	index map[string]*ManifestEntry
+}
+
+func (self *ManifestEntry) Name() string  { return strings.TrimSuffix(NameRegEx.FindString(self.Path), "/") }
+func (self *ManifestEntry) IsDir() bool   { return isDir(self.Path) }
+// >>>
+
+type manifestFileInfo struct {// <<<
+	// makes a manifest side look like a directory to statPath()
+	entry *ManifestEntry
+}
+
+func (self manifestFileInfo) Name() string       { return self.entry.Name() }
+func (self manifestFileInfo) Size() int64        { return self.entry.Size }
+func (self manifestFileInfo) Mode() fs.FileMode  { return self.entry.Mode }
+func (self manifestFileInfo) ModTime() time.Time { return self.entry.ModTime }
+func (self manifestFileInfo) IsDir() bool        { return self.entry.IsDir() }
+func (self manifestFileInfo) Sys() any           { return self.entry }
+// >>>
+
+func (self *Manifest) resolve(normpath string) string {// <<<
+	// with --symlinks=follow a link to a file inside the side stands for that file,
+	// links to folders, outside of it and broken links stay links
+	var Target string = normpath
+
+	if Arg_Symlinks != "follow" {
+		return normpath
+	}
+
+	for i:=0; i < 40; i++ {
+		E, Exists := self.index[Target]
+		if Exists == false || E.IsDir() {
+			return normpath
+		}
+		if E.Mode & fs.ModeSymlink == 0 {
+			return Target
+		}
+		if path.IsAbs(E.Target) {
+			return normpath
+		}
+		Target = path.Join(path.Dir(Target), E.Target)
+	}
+
+	return normpath
+}// >>>
+
+func (self *Manifest) Stat(normpath string) (fs.FileInfo, error) {// <<<
+	// folders are looked up with and without trailing slash, like os.Stat does
+	normpath = self.resolve(normpath)
+	if E, Exists := self.index[normpath]; Exists {
+		return manifestFileInfo{E}, nil
+	}
+	if E, Exists := self.index[strings.TrimSuffix(normpath, "/") + "/"]; Exists {
+		return manifestFileInfo{E}, nil
+	}
+	if E, Exists := self.index[strings.TrimSuffix(normpath, "/")]; Exists {
+		return manifestFileInfo{E}, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: normpath, Err: fs.ErrNotExist}
+}// >>>
+
+func (self *Manifest) Checksum(normpath string, algo string) (string, error) {// <<<
+	if algo != self.Hash {
+		return "", fmt.Errorf("manifest '%s' contains %s checksums, not %s", self.Root, self.Hash, algo)
+	}
+	E, Exists := self.index[self.resolve(normpath)]
+	if Exists == false {
+		return "", &fs.PathError{Op: "checksum", Path: normpath, Err: fs.ErrNotExist}
+	}
+	return E.Checksum, nil
+}// >>>
+
+func (self *Manifest) Open(normpath string) (io.ReadCloser, error) {// <<<
+	return nil, fmt.Errorf("manifest '%s' has no file contents", self.Root)
+}// >>>
+
+func (self *Manifest) Readlink(normpath string) string {// <<<
+	if E, Exists := self.index[normpath]; Exists {
+		return E.Target
+	}
+	return ""
+}// >>>
+
+func (self *Manifest) Contents(SetOfPaths map[string]struct{}, Rules *IgnoreRules) error {// <<<
+	return getManifestContents(self, SetOfPaths)
+}// >>>
+
+func sortManifestEntries(entries []ManifestEntry) {// <<<
+	// folders come before their content, getManifestContents() relies on it
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+}// >>>
+
+func loadManifest(fpath string) (*Manifest, error) {// <<<
+	Content, Err := os.ReadFile(fpath)
+	if Err != nil {
+		return nil, Err
+	}
+
+	var Result Manifest
+	if Err := json.Unmarshal(Content, &Result); Err != nil {
+		return nil, fmt.Errorf("'%s' is not a manifest: %s", fpath, Err)
+	}
+
+	if Result.SchemaVersion < 1 || Result.SchemaVersion > ManifestSchemaVersion {
+		return nil, fmt.Errorf("'%s' has unsupported manifest schema version %d", fpath, Result.SchemaVersion)
+	}
+
+	Result.index = make(map[string]*ManifestEntry)
+	for i := range Result.Entries {
+		Result.index[Result.Entries[i].Path] = &Result.Entries[i]
+	}
+
+	return &Result, nil
+}// >>>
+
+func getManifestContents(manifest *Manifest, SetOfPaths map[string]struct{}) error {// <<<
+	// applies the same filters as the directory walker
+	var SkippedDirs []string
+
+	NextEntry:
+	for _, E := range manifest.Entries {
+		for _, d := range SkippedDirs {
+			if strings.HasPrefix(E.Path, d) {
+				continue NextEntry
+			}
+		}
+
+		// links can not be followed inside a manifest, but they can be left out
+		if Arg_Symlinks == "ignore" && E.Mode & fs.ModeSymlink != 0 {
+			continue
+		}
+
+		// only --ignore-file applies, the .gitignore files are not part of the manifest
+		if isIgnoredPath(nil, strings.TrimSuffix(E.Path, "/"), E.IsDir()) {
+			if E.IsDir() {
+				SkippedDirs = append(SkippedDirs, E.Path)
+			}
+			continue
+		}
+
+		if addToSetOfPaths(SetOfPaths, strings.TrimSuffix(E.Path, "/"), E.IsDir()) == filepath.SkipDir && E.IsDir() {
+			SkippedDirs = append(SkippedDirs, E.Path)
+		}
+	}
+
+	return nil
+}// >>>
+
+func createManifest(root string) (*Manifest, error) {// <<<
+	var SetOfPaths = make(map[string]struct{})
+	var ListOfPaths []string
+
+	var Rules IgnoreRules
+
+	if Err := getDirContents(root, SetOfPaths, &Rules); Err != nil {
+		return nil, Err
+	}
+
+	for p := range SetOfPaths {
+		ListOfPaths = append(ListOfPaths, p)
+	}
+	sort.Strings(ListOfPaths)
+
+	Result := Manifest{
+		SchemaVersion : ManifestSchemaVersion,
+		Version       : Version,
+		Root          : root,
+		Created       : time.Now(),
+		Hash          : HashAlgorithm,
+		Entries       : make([]ManifestEntry, len(ListOfPaths)),
+	}
+
+	var Errors = make([]error, len(ListOfPaths))
+
+	runParallel(len(ListOfPaths), func(i int) {
+		FileInfo, Err := statPath(root, ListOfPaths[i])
+		if Err != nil {
+			Errors[i] = Err
+			return
+		}
+
+		Result.Entries[i] = ManifestEntry{
+			Path    : ListOfPaths[i],
+			Mode    : FileInfo.Mode(),
+		}
+
+		if Uid, Gid := getFileOwner(FileInfo); Uid >= 0 {
+			Result.Entries[i].Uid = &Uid
+			Result.Entries[i].Gid = &Gid
+		}
+
+		if FileInfo.Mode() & fs.ModeSymlink != 0 {
+			Result.Entries[i].Size   = FileInfo.Size()
+			Result.Entries[i].Target = readLink(root, ListOfPaths[i])
+		} else if FileInfo.IsDir() == false {
+			Result.Entries[i].Size     = FileInfo.Size()
+			Result.Entries[i].ModTime  = FileInfo.ModTime()
+			Result.Entries[i].Checksum, Errors[i] = getPathChecksum(root, ListOfPaths[i], HashAlgorithm)
+		}
+	})
+
+	for _, Err := range Errors {
+		if Err != nil {
+			return nil, Err
+		}
+	}
+
+	return &Result, nil
+}// >>>
+
+func writeManifest(manifest *Manifest, output string) error {// <<<
+	var Writer = os.Stdout
+
+	if output != "" && output != "-" {
+		File, Err := os.Create(output)
+		if Err != nil {
+			return Err
+		}
+		defer File.Close()
+		Writer = File
+	}
+
+	Encoder := json.NewEncoder(Writer)
+	Encoder.SetIndent("", "  ")
+	return Encoder.Encode(manifest)
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
@@ -0,0 +1,246 @@
+package main
+
+// imports <<<
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+	"diffee/tree"
+) // >>>
+
+// Enums <<<
+type MergeState int
+const (
+	Unchanged MergeState = iota
+	ChangedLeft
+	ChangedRight
+	ChangedBoth // changed the same way on both sides
+	Conflict
+	AddedLeft
+	AddedRight
+	DeletedLeft
+	DeletedRight
+)
+// >>>
+
+// Variables <<<
+var (
+	MergeStateNames = map[MergeState]string{
+		Unchanged:    "unchanged",
+		ChangedLeft:  "changed-left",
+		ChangedRight: "changed-right",
+		ChangedBoth:  "changed-both",
+		Conflict:     "conflict",
+		AddedLeft:    "added-left",
+		AddedRight:   "added-right",
+		DeletedLeft:  "deleted-left",
+		DeletedRight: "deleted-right",
+	}
+)
+// >>>
+
+func isSameOnSides(E *Entry, roots map[string]string, a string, b string) bool {// <<<
+	// compares two sides of an entry with the active comparison,
+	// checksums that are still missing are computed on the way
+	if E.IsMissing[a] || E.IsMissing[b] {
+		return E.IsMissing[a] == E.IsMissing[b]
+	}
+
+	if E.IsLink[a] || E.IsLink[b] {
+		return E.IsLink[a] == E.IsLink[b] && E.LinkTarget[a] == E.LinkTarget[b]
+	}
+
+	// like isSameEntry() the sides differ as soon as one criterion differs
+	Criteria := getActiveCriteria()
+	if len(Criteria) == 0 {
+		Criteria = []string{"hash"}
+	}
+
+	for _, c := range Criteria {
+		switch {
+		case c == "mode":
+			if getModeBits(E.Mode[a]) != getModeBits(E.Mode[b]) {
+				return false
+			}
+		case c == "owner":
+			if E.Uid[a] != E.Uid[b] || E.Gid[a] != E.Gid[b] {
+				return false
+			}
+		case E.IsDir: // folders have no size, time or content
+		case c == "time":
+			if getTimeDiffState(E.ModTime[a], E.ModTime[b]) != SameTime {
+				return false
+			}
+		case E.Size[a] != E.Size[b]: // size, bytes and hash
+			return false
+		case c == "bytes":
+			Offset, Err := compareBytes(roots[a], roots[b], E.NormPath)
+			if Err != nil {
+				printError(fmt.Sprintf("could not compare '%s': %s", E.NormPath, Err))
+				HasWalkErrors.Store(true)
+				return false
+			}
+			if Offset >= 0 {
+				return false
+			}
+		case c == "hash":
+			for _, side := range []string{a, b} {
+				if E.Checksum[side] == "" {
+					Checksum, Err := getPathChecksum(roots[side], E.NormPath, HashAlgorithm)
+					if Err != nil {
+						printError(fmt.Sprintf("could not hash '%s': %s", E.Path[side], Err))
+						HasWalkErrors.Store(true)
+						return false
+					}
+					E.Checksum[side] = Checksum
+				}
+			}
+			if E.Checksum[a] != E.Checksum[b] {
+				return false
+			}
+		}
+	}
+
+	return true
+}// >>>
+
+func getMergeState(E *Entry, roots map[string]string) MergeState {// <<<
+	LeftChanged  := !isSameOnSides(E, roots, "base", "left")
+	RightChanged := !isSameOnSides(E, roots, "base", "right")
+
+	if LeftChanged == false && RightChanged == false {
+		return Unchanged
+	}
+
+	if RightChanged == false {
+		if E.IsMissing["base"] {
+			return AddedLeft
+		} else if E.IsMissing["left"] {
+			return DeletedLeft
+		}
+		return ChangedLeft
+	}
+
+	if LeftChanged == false {
+		if E.IsMissing["base"] {
+			return AddedRight
+		} else if E.IsMissing["right"] {
+			return DeletedRight
+		}
+		return ChangedRight
+	}
+
+	if isSameOnSides(E, roots, "left", "right") {
+		return ChangedBoth
+	}
+	return Conflict
+}// >>>
+
+func updateMergeStates(baseroot string, content *[]Entry) {// <<<
+	// adds the base as third side to every entry and classifies it
+	Roots := (*content)[0].Path
+	Roots["base"] = baseroot
+
+	runParallel(len(*content)-1, func(i int) {
+		E := &(*content)[i+1]
+
+		E.Path["base"] = baseroot + E.NormPath
+		E.IsMissing["base"] = true
+		E.Uid["base"], E.Gid["base"] = -1, -1
+
+		FileInfo, Err := statPath(baseroot, E.NormPath)
+		if Err == nil && FileInfo.IsDir() == E.IsDir {
+			E.IsMissing["base"] = false
+			E.Mode["base"] = FileInfo.Mode()
+			E.Uid["base"], E.Gid["base"] = getOwner(FileInfo)
+			if FileInfo.Mode() & fs.ModeSymlink != 0 {
+				E.IsLink["base"]     = true
+				E.LinkTarget["base"] = readLink(baseroot, E.NormPath)
+			}
+			if E.IsDir == false {
+				E.Size["base"]    = FileInfo.Size()
+				E.ModTime["base"] = FileInfo.ModTime()
+			}
+		}
+
+		// a path that only exists in the base is no orphan on either side
+		if E.IsMissing["left"] && E.IsMissing["right"] {
+			E.IsOrphan["left"]  = false
+			E.IsOrphan["right"] = false
+		}
+
+		E.MergeState = getMergeState(E, Roots)
+	})
+}// >>>
+
+func getMergeDecoration(entry *Entry, side string, info bool) (lipgloss.Style, string) {// <<<
+	var Style lipgloss.Style = lipgloss.NewStyle()
+	var Info string = ""
+
+	switch (*entry).MergeState {
+	case ChangedLeft:
+		if side == "left" { Style = StyleDiff }
+	case ChangedRight:
+		if side == "right" { Style = StyleDiff }
+	case AddedLeft:
+		if side == "left" { Style = StyleOrphan }
+	case AddedRight:
+		if side == "right" { Style = StyleOrphan }
+	case ChangedBoth:
+		if side != "base" { Style = StyleDiff }
+	case Conflict:
+		Style = StyleConflict
+		if side != "base" { Info = " !" }
+	}
+
+	if info && side == "base" && (*entry).MergeState != Unchanged {
+		Info = " (" + MergeStateNames[(*entry).MergeState] + ")"
+	}
+
+	return Style, Info
+}// >>>
+
+func copyHiddenNodes(from *tree.Node, to *tree.Node) {// <<<
+	// applies the visibility of one tree to another tree built from the same slice
+	to.HideNode(from.IsHidden())
+
+	if len(from.GetChildren()) != len(to.GetChildren()) {
+		return
+	}
+
+	for i := 0; i < len(from.GetChildren()); i++ {
+		copyHiddenNodes(from.GetChild(i+1), to.GetChild(i+1))
+	}
+}// >>>
+
+func printThreeWay(contents *[]Entry) {// <<<
+
+	var LeftTree  = convertSliceToTree(contents, "left")
+	var BaseTree  = convertSliceToTree(contents, "base")
+	var RightTree = convertSliceToTree(contents, "right")
+
+	var ColumnWidth int = 0
+	TermWidth, _, Err := term.GetSize(0)
+	if Err == nil {
+		ColumnWidth = (TermWidth-2*RightSideOffset)/3
+	}
+
+	LeftRootDisplay, RightRootDisplay := getRootDisplay(contents, ColumnWidth)
+	BaseRootDisplay, _ := shortenPath((*contents)[0].Path["base"], "", ColumnWidth)
+
+	LeftTree.Node.SetText(StyleRoot.Render(LeftRootDisplay))
+	BaseTree.Node.SetText(StyleRoot.Render(BaseRootDisplay))
+	RightTree.Node.SetText(StyleRoot.Render(RightRootDisplay))
+
+	filterTrees(&LeftTree.Node, &RightTree.Node)
+	copyHiddenNodes(&LeftTree.Node, &BaseTree.Node)
+
+	fmt.Println(lipgloss.JoinHorizontal(lipgloss.Top,
+		strings.Join(LeftTree.RenderTree(), "\n"),
+		strings.Join(BaseTree.SetRenderOffset(RightSideOffset).RenderTree(), "\n"),
+		strings.Join(RightTree.SetRenderOffset(RightSideOffset).RenderTree(), "\n")))
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
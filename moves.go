@@ -0,0 +1,139 @@
+package main
+
+// imports <<<
+import (
+	"fmt"
+	"github.com/pmezard/go-difflib/difflib"
+) // >>>
+
+func setMovePair(left *Entry, right *Entry, similarity int) {// <<<
+	left.MovePath    = right.NormPath
+	right.MovePath   = left.NormPath
+	left.Similarity  = similarity
+	right.Similarity = similarity
+}// >>>
+
+func getOrphanFiles(content *[]Entry, side string) []*Entry {// <<<
+	// empty files all look the same, so they are never matched
+	var Result []*Entry
+
+	for i:=1; i < len(*content); i++ {
+		E := &(*content)[i]
+		if E.IsDir == false && E.IsOrphan[side] && E.IsLink[side] == false && E.Size[side] > 0 {
+			Result = append(Result, E)
+		}
+	}
+
+	return Result
+}// >>>
+
+func updateMoves(content *[]Entry) {// <<<
+	// matches left orphans to right orphans with the same content,
+	// files with the same name are preferred if there are several candidates
+	Roots        := (*content)[0].Path
+	LeftOrphans  := getOrphanFiles(content, "left")
+	RightOrphans := getOrphanFiles(content, "right")
+
+	// orphans are never hashed by updateChecksums()
+	Orphans := append(append([]*Entry{}, LeftOrphans...), RightOrphans...)
+	runParallel(len(Orphans), func(i int) {
+		for _, Side := range []string{"left", "right"} {
+			if Orphans[i].IsOrphan[Side] == false || Orphans[i].Checksum[Side] != "" {
+				continue
+			}
+			// an orphan that can't be read is never matched
+			Checksum, Err := getPathChecksum(Roots[Side], Orphans[i].NormPath, HashAlgorithm)
+			if Err != nil {
+				printError(fmt.Sprintf("could not hash '%s': %s", Orphans[i].Path[Side], Err))
+				HasWalkErrors.Store(true)
+			}
+			Orphans[i].Checksum[Side] = Checksum
+		}
+	})
+
+	Candidates := make(map[string][]*Entry)
+	for _, R := range RightOrphans {
+		if R.Checksum["right"] != "" {
+			Key := fmt.Sprintf("%d:%s", R.Size["right"], R.Checksum["right"])
+			Candidates[Key] = append(Candidates[Key], R)
+		}
+	}
+
+	for _, L := range LeftOrphans {
+		Key  := fmt.Sprintf("%d:%s", L.Size["left"], L.Checksum["left"])
+		List := Candidates[Key]
+		if L.Checksum["left"] == "" || len(List) == 0 {
+			continue
+		}
+
+		Best := 0
+		for c := range List {
+			if List[c].Name == L.Name {
+				Best = c
+				break
+			}
+		}
+
+		setMovePair(L, List[Best], 100)
+		Candidates[Key] = append(List[:Best:Best], List[Best+1:]...)
+	}
+
+	if Arg_Similarity < 100 {
+		updateSimilarMoves(Roots, LeftOrphans, RightOrphans)
+	}
+}// >>>
+
+func updateSimilarMoves(roots map[string]string, leftorphans []*Entry, rightorphans []*Entry) {// <<<
+	// matches the remaining text files whose lines are at least Arg_Similarity percent alike
+	var Threshold float64 = float64(Arg_Similarity) / 100
+	var RightLines = make(map[*Entry][]string)
+
+	for _, R := range rightorphans {
+		if R.MovePath != "" {
+			continue
+		}
+		if IsBinary, Err := isBinaryFile(roots["right"], R.NormPath); Err != nil || IsBinary {
+			continue
+		}
+		if Lines, Err := readLines(roots["right"], R.NormPath); Err == nil {
+			RightLines[R] = Lines
+		}
+	}
+
+	for _, L := range leftorphans {
+		if L.MovePath != "" {
+			continue
+		}
+		if IsBinary, Err := isBinaryFile(roots["left"], L.NormPath); Err != nil || IsBinary {
+			continue
+		}
+		LeftLines, Err := readLines(roots["left"], L.NormPath)
+		if Err != nil {
+			continue
+		}
+
+		var Best      *Entry  = nil
+		var BestRatio float64 = 0
+
+		for _, R := range rightorphans {
+			Lines, IsText := RightLines[R]
+			if IsText == false || R.MovePath != "" {
+				continue
+			}
+
+			Matcher := difflib.NewMatcher(LeftLines, Lines)
+			if Matcher.RealQuickRatio() < Threshold || Matcher.QuickRatio() < Threshold {
+				continue
+			}
+			if Ratio := Matcher.Ratio(); Ratio >= Threshold && Ratio > BestRatio {
+				Best, BestRatio = R, Ratio
+			}
+		}
+
+		if Best != nil {
+			setMovePair(L, Best, int(BestRatio*100))
+		}
+	}
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
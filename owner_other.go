@@ -0,0 +1,15 @@
+//go:build !unix
+
+package main
+
+// imports <<<
+import (
+	"io/fs"
+) // >>>
+
+func getFileOwner(info fs.FileInfo) (int, int) {// <<<
+	// there are no uids and gids outside of unix
+	return -1, -1
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
@@ -0,0 +1,18 @@
+//go:build unix
+
+package main
+
+// imports <<<
+import (
+	"io/fs"
+	"syscall"
+) // >>>
+
+func getFileOwner(info fs.FileInfo) (int, int) {// <<<
+	if Stat, IsStat := info.Sys().(*syscall.Stat_t); IsStat {
+		return int(Stat.Uid), int(Stat.Gid)
+	}
+	return -1, -1
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
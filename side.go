@@ -0,0 +1,183 @@
+package main
+
+// imports <<<
+import (
+	"os"
+	"io"
+	"path"
+	"io/fs"
+	"strings"
+) // >>>
+
+// Side interface <<<
+// a side is everything that can be compared like a folder, normpath is relative to its root
+// and folders may end with a slash
+type Side interface {
+	Stat(normpath string) (fs.FileInfo, error) // links are only followed as --symlinks says
+	Readlink(normpath string) string
+	Checksum(normpath string, algo string) (string, error)
+	Open(normpath string) (io.ReadCloser, error) // the content of a file, links are followed like Stat does
+	Contents(SetOfPaths map[string]struct{}, Rules *IgnoreRules) error // Rules collects the ignore rules of the side
+}
+// >>>
+
+// Variables <<<
+var (
+	// sides that are no folder on disk, keyed by the root as used in Entry.Path
+	Sides = make(map[string]Side)
+)
+// >>>
+
+func getSide(root string) Side {// <<<
+	if S, Exists := Sides[root]; Exists {
+		return S
+	}
+	return dirSide{root}
+}// >>>
+
+type dirSide struct {// <<<
+	// a folder on disk
+	root string
+}
+
+func (self dirSide) Stat(normpath string) (fs.FileInfo, error) {
+	// a trailing slash would make Lstat follow the link
+	FileInfo, Err := os.Lstat(strings.TrimSuffix(self.root + normpath, "/"))
+	if Err != nil || FileInfo.Mode() & fs.ModeSymlink == 0 || Arg_Symlinks != "follow" {
+		return FileInfo, Err
+	}
+
+	// the walker keeps broken links and loops as link, they have no trailing slash
+	if Target, Err := os.Stat(self.root + normpath); Err == nil && (Target.IsDir() == false || isDir(normpath)) {
+		return Target, nil
+	}
+	return FileInfo, nil
+}
+
+func (self dirSide) Readlink(normpath string) string {
+	Target, _ := os.Readlink(self.root + normpath)
+	return Target
+}
+
+func (self dirSide) Checksum(normpath string, algo string) (string, error) {
+	return getChecksum(self.root + normpath, algo)
+}
+
+func (self dirSide) Open(normpath string) (io.ReadCloser, error) {
+	return os.Open(self.root + normpath)
+}
+
+func (self dirSide) Contents(SetOfPaths map[string]struct{}, Rules *IgnoreRules) error {
+	RootRules := getRootIgnoreRules(self.root)
+	*Rules = append(*Rules, RootRules...)
+
+	return walkDir(self.root, "", nil, RootRules, SetOfPaths, Rules)
+}
+// >>>
+
+// linkFS interface <<<
+// the methods of fs.ReadLinkFS, which is new in Go 1.25, an fs.FS without them has no links
+type linkFS interface {
+	ReadLink(name string) (string, error)
+	Lstat(name string) (fs.FileInfo, error)
+}
+// >>>
+
+type fsSide struct {// <<<
+	// any fs.FS, e.g. an in-memory tree for tests
+	fsys fs.FS
+}
+
+func newFSSide(fsys fs.FS) Side {
+	return fsSide{fsys}
+}
+
+func (self fsSide) lstat(name string) (fs.FileInfo, error) {
+	if Links, HasLinks := self.fsys.(linkFS); HasLinks {
+		return Links.Lstat(name)
+	}
+	return fs.Stat(self.fsys, name)
+}
+
+func (self fsSide) resolve(normpath string) string {
+	// like Manifest.resolve(), with --symlinks=follow a link to a file inside the side stands for that file,
+	// links to folders, outside of it and broken links stay links
+	var Target string = strings.TrimSuffix(normpath, "/")
+
+	if Arg_Symlinks != "follow" {
+		return normpath
+	}
+
+	for i:=0; i < 40; i++ {
+		FileInfo, Err := self.lstat(Target)
+		if Err != nil || FileInfo.IsDir() {
+			return normpath
+		}
+		if FileInfo.Mode() & fs.ModeSymlink == 0 {
+			return Target
+		}
+		Link := self.Readlink(Target)
+		if path.IsAbs(Link) || fs.ValidPath(path.Join(path.Dir(Target), Link)) == false {
+			return normpath
+		}
+		Target = path.Join(path.Dir(Target), Link)
+	}
+
+	return normpath
+}
+
+func (self fsSide) Stat(normpath string) (fs.FileInfo, error) {
+	return self.lstat(strings.TrimSuffix(self.resolve(normpath), "/"))
+}
+
+func (self fsSide) Readlink(normpath string) string {
+	if Links, HasLinks := self.fsys.(linkFS); HasLinks {
+		Target, _ := Links.ReadLink(strings.TrimSuffix(normpath, "/"))
+		return Target
+	}
+	return ""
+}
+
+func (self fsSide) Checksum(normpath string, algo string) (string, error) {
+	File, Err := self.Open(normpath)
+	if Err != nil {
+		return "", Err
+	}
+	defer File.Close()
+
+	return getChecksumReader(File, algo)
+}
+
+func (self fsSide) Open(normpath string) (io.ReadCloser, error) {
+	return self.fsys.Open(self.resolve(normpath))
+}
+
+func (self fsSide) Contents(SetOfPaths map[string]struct{}, Rules *IgnoreRules) error {
+	// applies the same filters as the directory walker, only --ignore-file is known here,
+	// fs.WalkDir never follows links, so links to folders stay links
+	return fs.WalkDir(self.fsys, ".", func(fpath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if fpath == "." {
+			return nil
+		}
+
+		if d.Type() & fs.ModeSymlink != 0 && Arg_Symlinks == "ignore" {
+			return nil
+		}
+
+		if isIgnoredPath(nil, fpath, d.IsDir()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		// like filepath.Walk, SkipDir on a file skips the remaining files of its folder
+		return addToSetOfPaths(SetOfPaths, fpath, d.IsDir())
+	})
+}
+// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
@@ -0,0 +1,199 @@
+package main
+
+// imports <<<
+import (
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+) // >>>
+
+func setTestSides(t *testing.T, left fstest.MapFS, right fstest.MapFS, symlinks string) {// <<<
+	// the sides and the policy are globals, they are restored after the test
+	OldSides, OldSymlinks := Sides, Arg_Symlinks
+
+	Sides = map[string]Side{"left/": newFSSide(left), "right/": newFSSide(right)}
+	Arg_Symlinks = symlinks
+
+	t.Cleanup(func() {
+		Sides, Arg_Symlinks = OldSides, OldSymlinks
+	})
+}// >>>
+
+func compareTestSides() ([]string, []Entry) {// <<<
+	var Union    []string
+	var Contents []Entry
+
+	getUnionSetOfDirContents([]string{"left/", "right/"}, &Union)
+	getDirContentInformation("left/", "right/", &Union, &Contents)
+	return Union, Contents
+}// >>>
+
+func getTestEntry(t *testing.T, contents []Entry, normpath string) *Entry {// <<<
+	for i := range contents {
+		if contents[i].NormPath == normpath {
+			return &contents[i]
+		}
+	}
+	t.Fatalf("no entry '%s'", normpath)
+	return nil
+}// >>>
+
+func TestFSSideContents(t *testing.T) {// <<<
+	setTestSides(t, fstest.MapFS{
+		"same.txt":      {Data: []byte("same")},
+		"left.txt":      {Data: []byte("left")},
+		"sub/diff.txt":  {Data: []byte("left")},
+	}, fstest.MapFS{
+		"same.txt":      {Data: []byte("same")},
+		"sub/diff.txt":  {Data: []byte("rite")},
+		"sub/right.txt": {Data: []byte("right")},
+	}, "follow")
+
+	Union, Contents := compareTestSides()
+
+	Expected := []string{"left.txt", "same.txt", "sub/", "sub/diff.txt", "sub/right.txt"}
+	if slices.Equal(Union, Expected) == false {
+		t.Fatalf("union is %v, expected %v", Union, Expected)
+	}
+
+	for Path, Status := range map[string]string{
+		"same.txt":      "same",
+		"left.txt":      "left-orphan",
+		"sub/":          "same",
+		"sub/diff.txt":  "different",
+		"sub/right.txt": "right-orphan",
+	} {
+		if Actual := getEntryStatus(getTestEntry(t, Contents, Path), "left", "right"); Actual != Status {
+			t.Errorf("%s is %s, expected %s", Path, Actual, Status)
+		}
+	}
+
+	if hasDifferences(&Contents) == false {
+		t.Error("the sides are reported as equal")
+	}
+}// >>>
+
+func TestFSSideRootSortsLast(t *testing.T) {// <<<
+	// names like "#x" sort before ".", the root must not take the place of such a file
+	setTestSides(t, fstest.MapFS{
+		"#x": {Data: []byte("same")},
+	}, fstest.MapFS{
+		"#x": {Data: []byte("same")},
+	}, "follow")
+
+	_, Contents := compareTestSides()
+
+	if len(Contents) != 2 || Contents[0].Path["left"] != "left/" || Contents[1].NormPath != "#x" {
+		t.Fatalf("unexpected entries %v", Contents)
+	}
+	if hasDifferences(&Contents) {
+		t.Error("equal sides are reported as different")
+	}
+}// >>>
+
+type unreadableFS struct {// <<<
+	// lists and stats its files, but they can't be opened
+	fstest.MapFS
+}
+
+func (self unreadableFS) Open(name string) (fs.File, error) {
+	if Info, Err := self.MapFS.Stat(name); Err == nil && Info.IsDir() {
+		return self.MapFS.Open(name)
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+}
+// >>>
+
+func TestUnreadableFiles(t *testing.T) {// <<<
+	// files that can't be read are different and make the exit code 2
+	Files := fstest.MapFS{"file.txt": {Data: []byte("same")}}
+	setTestSides(t, Files, Files, "follow")
+	Sides["left/"]  = newFSSide(unreadableFS{Files})
+	Sides["right/"] = newFSSide(unreadableFS{Files})
+	OldBytes := Arg_Bytes
+	t.Cleanup(func() { HasWalkErrors.Store(false); Arg_Bytes = OldBytes })
+
+	// the checksum first, then --bytes
+	for _, Arg_Bytes = range []bool{false, true} {
+		HasWalkErrors.Store(false)
+		_, Contents := compareTestSides()
+
+		if Actual := getEntryStatus(getTestEntry(t, Contents, "file.txt"), "left", "right"); Actual != "different" {
+			t.Errorf("bytes %t: file.txt is %s, expected different", Arg_Bytes, Actual)
+		}
+		if Code := getExitCode(&Contents); Code != INTERNAL {
+			t.Errorf("bytes %t: exit code is %d, expected %d", Arg_Bytes, Code, INTERNAL)
+		}
+	}
+}// >>>
+
+func TestFSSideSymlinks(t *testing.T) {// <<<
+	if _, HasLinks := any(fstest.MapFS{}).(linkFS); HasLinks == false {
+		t.Skip("fstest.MapFS has no links before Go 1.25")
+	}
+
+	// the link on the left has the same content as the file on the right
+	Left := fstest.MapFS{
+		"file.txt":  {Data: []byte("content")},
+		"link":      {Data: []byte("file.txt"), Mode: fs.ModeSymlink},
+		"left-link": {Data: []byte("file.txt"), Mode: fs.ModeSymlink},
+	}
+	Right := fstest.MapFS{
+		"file.txt": {Data: []byte("content")},
+		"link":     {Data: []byte("content")},
+	}
+
+	for _, Test := range []struct {
+		Policy string
+		Status string
+		IsLink bool
+	}{
+		{"follow",         "same",         false},
+		{"compare-target", "different",    true},
+		{"ignore",         "right-orphan", false},
+	} {
+		setTestSides(t, Left, Right, Test.Policy)
+		Union, Contents := compareTestSides()
+
+		if slices.Contains(Union, "left-link") == (Test.Policy == "ignore") {
+			t.Errorf("%s: left-link is listed: %t", Test.Policy, slices.Contains(Union, "left-link"))
+		}
+
+		E := getTestEntry(t, Contents, "link")
+		if Actual := getEntryStatus(E, "left", "right"); Actual != Test.Status {
+			t.Errorf("%s: link is %s, expected %s", Test.Policy, Actual, Test.Status)
+		}
+		if E.IsLink["left"] != Test.IsLink {
+			t.Errorf("%s: link is a link: %t, expected %t", Test.Policy, E.IsLink["left"], Test.IsLink)
+		}
+		if Test.IsLink && E.LinkTarget["left"] != "file.txt" {
+			t.Errorf("%s: link target is '%s', expected 'file.txt'", Test.Policy, E.LinkTarget["left"])
+		}
+	}
+}// >>>
+
+func TestFSSideContentDiff(t *testing.T) {// <<<
+	// --bytes and --content read the files through the sides
+	setTestSides(t, fstest.MapFS{
+		"file.txt": {Data: []byte("one\ntwo\nthree\n")},
+	}, fstest.MapFS{
+		"file.txt": {Data: []byte("one\n2\nthree\n")},
+	}, "follow")
+
+	if Offset, Err := compareBytes("left/", "right/", "file.txt"); Err != nil || Offset != 4 {
+		t.Errorf("first difference at %d, expected 4: %v", Offset, Err)
+	}
+
+	Lines, Err := getContentDiff("left/", "right/", "file.txt", 0)
+	if Err != nil {
+		t.Fatal(Err)
+	}
+	Diff := strings.Join(Lines, "\n")
+	if strings.Contains(Diff, "-two") == false || strings.Contains(Diff, "+2") == false {
+		t.Errorf("unexpected diff:\n%s", Diff)
+	}
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
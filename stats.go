@@ -0,0 +1,135 @@
+package main
+
+// imports <<<
+import (
+	"fmt"
+	"sort"
+	"strconv"
+) // >>>
+
+// Stats struct <<<
+type Stats struct {
+	Files        int
+	Folders      int
+	Same         int // files on both sides that compare equal
+	Different    int
+	Orphans      map[string]int // files and folders that only exist on that side
+	BytesAdded   int64 // from the left to the right side, including orphans
+	BytesRemoved int64
+	Deltas       []*Entry // files with the largest size difference first
+}
+// >>>
+
+// Variables <<<
+var (
+	StatDeltaCount int = 5
+)
+// >>>
+
+func getSizeDelta(E *Entry, left string, right string) int64 {// <<<
+	// a missing side counts as empty file
+	var Delta int64 = 0
+
+	if E.IsMissing[right] == false {
+		Delta = Delta + E.Size[right]
+	}
+	if E.IsMissing[left] == false {
+		Delta = Delta - E.Size[left]
+	}
+	return Delta
+}// >>>
+
+func getStats(contents *[]Entry, left string, right string) Stats {// <<<
+	// only the entries that are printed are counted
+	var Result = Stats{Orphans: make(map[string]int)}
+
+	for i:=1; i < len(*contents); i++ {
+		E := &(*contents)[i]
+		if shouldHideEntry(E) {
+			continue
+		}
+
+		if E.IsDir {
+			Result.Folders++
+		} else {
+			Result.Files++
+		}
+
+		if E.IsOrphan[left] {
+			Result.Orphans[left]++
+		} else if E.IsOrphan[right] {
+			Result.Orphans[right]++
+		} else if isDifferentEntry(E) {
+			Result.Different++
+		} else if E.IsDir == false {
+			Result.Same++
+		}
+
+		if E.IsDir || isLinkEntry(E) {
+			continue
+		}
+
+		if Delta := getSizeDelta(E, left, right); Delta > 0 {
+			Result.BytesAdded = Result.BytesAdded + Delta
+			Result.Deltas = append(Result.Deltas, E)
+		} else if Delta < 0 {
+			Result.BytesRemoved = Result.BytesRemoved - Delta
+			Result.Deltas = append(Result.Deltas, E)
+		}
+	}
+
+	sort.SliceStable(Result.Deltas, func(i, j int) bool {
+		a, b := getSizeDelta(Result.Deltas[i], left, right), getSizeDelta(Result.Deltas[j], left, right)
+		return max(a, -a) > max(b, -b)
+	})
+	if len(Result.Deltas) > StatDeltaCount {
+		Result.Deltas = Result.Deltas[:StatDeltaCount]
+	}
+
+	return Result
+}// >>>
+
+func getStatLines(stats Stats, left string, right string) [][2]string {// <<<
+	// label and value of every line, shared by the terminal and the html output
+	return [][2]string{
+		{"files",               strconv.Itoa(stats.Files)},
+		{"folders",             strconv.Itoa(stats.Folders)},
+		{"same",                strconv.Itoa(stats.Same)},
+		{"different",           strconv.Itoa(stats.Different)},
+		{left + " orphans",     strconv.Itoa(stats.Orphans[left])},
+		{right + " orphans",    strconv.Itoa(stats.Orphans[right])},
+		{"bytes added",         "+" + formatSize(stats.BytesAdded)},
+		{"bytes removed",       "-" + formatSize(stats.BytesRemoved)},
+	}
+}// >>>
+
+func printStats(contents *[]Entry) {// <<<
+	var Left  string = "left"
+	var Right string = "right"
+
+	if Arg_Swap {
+		Left, Right = Right, Left
+	}
+
+	Stats := getStats(contents, Left, Right)
+
+	for _, l := range getStatLines(Stats, Left, Right) {
+		fmt.Printf("%-14s %s\n", l[0], l[1])
+	}
+
+	if len(Stats.Deltas) == 0 {
+		return
+	}
+
+	fmt.Println("largest size deltas")
+	for _, E := range Stats.Deltas {
+		Delta := getSizeDelta(E, Left, Right)
+		if Delta >= 0 {
+			fmt.Printf("  %10s  %s\n", "+" + formatSize(Delta), E.NormPath)
+		} else {
+			fmt.Printf("  %10s  %s\n", "-" + formatSize(-Delta), E.NormPath)
+		}
+	}
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
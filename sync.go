@@ -0,0 +1,223 @@
+package main
+
+// imports <<<
+import (
+	"os"
+	"io"
+	"io/fs"
+	"fmt"
+	"strings"
+) // >>>
+
+// Enums <<<
+type SyncAction int
+const (
+	SyncCopy SyncAction = iota
+	SyncUpdate
+	SyncMkdir
+	SyncDelete
+	SyncLink
+)
+// >>>
+
+// Variables <<<
+var (
+	SyncActionNames = map[SyncAction]string{
+		SyncCopy:   "copy",
+		SyncUpdate: "update",
+		SyncMkdir:  "mkdir",
+		SyncDelete: "delete",
+		SyncLink:   "link",
+	}
+)
+// >>>
+
+type SyncStep struct {// <<<
+	Action SyncAction
+	Source string // empty for SyncDelete
+	Target string
+}// >>>
+
+func getSyncSteps(contents *[]Entry, from string, to string) []SyncStep {// <<<
+	// deletions come first, so a file can replace a folder of the same name and vice versa
+	var Deletions []SyncStep
+	var Copies    []SyncStep
+	var Deleted   []string
+
+	for i:=1; i < len(*contents); i++ {
+		E := &(*contents)[i]
+
+		if shouldHideEntry(E) {
+			continue
+		}
+
+		if E.IsOrphan[to] {
+			// the root is never deleted, whatever ends up in the entries
+			if Arg_SyncDelete == false || E.NormPath == "" || E.NormPath == "." {
+				continue
+			}
+			// the content of a deleted folder is gone as well
+			IsDeleted := false
+			for _, d := range Deleted {
+				if strings.HasPrefix(E.NormPath, d) {
+					IsDeleted = true
+				}
+			}
+			if IsDeleted == false {
+				Deletions = append(Deletions, SyncStep{Action: SyncDelete, Target: E.Path[to]})
+				if E.IsDir {
+					Deleted = append(Deleted, E.NormPath)
+				}
+			}
+			continue
+		}
+
+		if E.IsLink[from] {
+			if E.IsOrphan[from] || isSameEntry(E) == false {
+				Copies = append(Copies, SyncStep{Action: SyncLink, Source: E.Path[from], Target: E.Path[to]})
+			}
+			continue
+		}
+
+		if E.IsOrphan[from] {
+			if E.IsDir {
+				Copies = append(Copies, SyncStep{Action: SyncMkdir, Source: E.Path[from], Target: E.Path[to]})
+			} else {
+				Copies = append(Copies, SyncStep{Action: SyncCopy, Source: E.Path[from], Target: E.Path[to]})
+			}
+			continue
+		}
+
+		if E.IsDir == false && isSameEntry(E) == false {
+			Copies = append(Copies, SyncStep{Action: SyncUpdate, Source: E.Path[from], Target: E.Path[to]})
+		}
+	}
+
+	return append(Deletions, Copies...)
+}// >>>
+
+func copyFile(source string, target string) error {// <<<
+	// copies the content, the permissions and the modification time
+	SourceInfo, Err := os.Stat(source)
+	if Err != nil {
+		return Err
+	}
+
+	// a link on the target side is replaced instead of overwriting the file it points to
+	if TargetInfo, Err := os.Lstat(target); Err == nil && TargetInfo.Mode() & fs.ModeSymlink != 0 {
+		if Err := os.Remove(target); Err != nil {
+			return Err
+		}
+	}
+
+	SourceFile, Err := os.Open(source)
+	if Err != nil {
+		return Err
+	}
+	defer SourceFile.Close()
+
+	TargetFile, Err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, SourceInfo.Mode().Perm())
+	if Err != nil {
+		return Err
+	}
+
+	if _, Err := io.Copy(TargetFile, SourceFile); Err != nil {
+		TargetFile.Close()
+		return Err
+	}
+	if Err := TargetFile.Close(); Err != nil {
+		return Err
+	}
+
+	// an existing file keeps its permissions when it is opened
+	if Err := os.Chmod(target, SourceInfo.Mode().Perm()); Err != nil {
+		return Err
+	}
+	return os.Chtimes(target, SourceInfo.ModTime(), SourceInfo.ModTime())
+}// >>>
+
+func runSyncStep(step SyncStep) error {// <<<
+	switch step.Action {
+	case SyncDelete:
+		return os.RemoveAll(step.Target)
+	case SyncMkdir:
+		SourceInfo, Err := os.Stat(step.Source)
+		if Err != nil {
+			return Err
+		}
+		// the permissions are set after the content was copied, a read-only folder would fail otherwise
+		return os.Mkdir(step.Target, SourceInfo.Mode().Perm() | 0700)
+	case SyncLink:
+		// links are created again instead of copying the file they point to
+		Target, Err := os.Readlink(step.Source)
+		if Err != nil {
+			return Err
+		}
+		if Err := os.Remove(step.Target); Err != nil && os.IsNotExist(Err) == false {
+			return Err
+		}
+		return os.Symlink(Target, step.Target)
+	}
+	return copyFile(step.Source, step.Target)
+}// >>>
+
+func isSyncRoot(target string, roots []string) bool {// <<<
+	// compares the real paths, so "b/." or a path through a link is caught as well
+	for _, r := range roots {
+		if getRealPath(target) == getRealPath(r) {
+			return true
+		}
+	}
+	return false
+}// >>>
+
+func runSync(steps []SyncStep, roots []string, dryrun bool) int {// <<<
+	var Failed bool = false
+
+	for _, s := range steps {
+		if s.Action == SyncDelete && isSyncRoot(s.Target, roots) {
+			printError(fmt.Sprintf("refusing to delete '%s', it is the root of a side", s.Target))
+			Failed = true
+			continue
+		}
+
+		if s.Action == SyncDelete {
+			fmt.Printf("%-6s %s\n", SyncActionNames[s.Action], s.Target)
+		} else {
+			fmt.Printf("%-6s %s -> %s\n", SyncActionNames[s.Action], s.Source, s.Target)
+		}
+
+		if dryrun {
+			continue
+		}
+
+		if Err := runSyncStep(s); Err != nil {
+			printError(fmt.Sprintf("could not %s '%s': %s", SyncActionNames[s.Action], s.Target, Err))
+			Failed = true
+		}
+	}
+
+	if dryrun == false {
+		// copying into a folder changes its modification time, so the folders are done last
+		for i := len(steps)-1; i >= 0; i-- {
+			if steps[i].Action != SyncMkdir {
+				continue
+			}
+			if SourceInfo, Err := os.Stat(steps[i].Source); Err == nil {
+				os.Chmod(steps[i].Target, SourceInfo.Mode().Perm())
+				os.Chtimes(steps[i].Target, SourceInfo.ModTime(), SourceInfo.ModTime())
+			}
+		}
+	}
+
+	if dryrun && len(steps) > 0 {
+		fmt.Println("dry run, nothing was changed, use --dry-run=false to apply")
+	}
+
+	if Failed {
+		return INTERNAL
+	}
+	return OK
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
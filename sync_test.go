@@ -0,0 +1,49 @@
+package main
+
+// imports <<<
+import (
+	"os"
+	"testing"
+	"path/filepath"
+) // >>>
+
+func TestSyncKeepsRoots(t *testing.T) {// <<<
+	// neither a root entry nor a path that resolves to a root is ever deleted
+	var Dir   string = t.TempDir()
+	var Left  string = filepath.Join(Dir, "a") + "/"
+	var Right string = filepath.Join(Dir, "b") + "/"
+
+	for _, d := range []string{Left, Right} {
+		if Err := os.Mkdir(d, 0755); Err != nil {
+			t.Fatal(Err)
+		}
+	}
+
+	OldDelete := Arg_SyncDelete
+	Arg_SyncDelete = true
+	t.Cleanup(func() { Arg_SyncDelete = OldDelete })
+
+	Contents := []Entry{
+		{Path: map[string]string{"left": Left, "right": Right}},
+		{
+			NormPath  : ".",
+			IsDir     : true,
+			Path      : map[string]string{"left": Left + ".", "right": Right + "."},
+			IsMissing : map[string]bool{"left": true, "right": false},
+			IsOrphan  : map[string]bool{"left": false, "right": true},
+		},
+	}
+	if Steps := getSyncSteps(&Contents, "left", "right"); len(Steps) != 0 {
+		t.Errorf("the root is synchronized: %v", Steps)
+	}
+
+	Steps := []SyncStep{{Action: SyncDelete, Target: Right + "."}}
+	if runSync(Steps, []string{Left, Right}, false) != INTERNAL {
+		t.Error("deleting the root is not refused")
+	}
+	if _, Err := os.Stat(Right); Err != nil {
+		t.Errorf("the root is gone: %s", Err)
+	}
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>
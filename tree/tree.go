@@ -55,6 +55,10 @@ func (self *Node) HideChildren(h bool) *Node {// <<<
 	return self
 }// >>>
 
+func (self *Node) ChildrenHidden() bool {// <<<
+	return self.hidechildren
+}// >>>
+
 func (self *Node) GetParent() *Node {// <<<
 	return self.parent
 }// >>>
@@ -291,4 +295,27 @@ func (self *Tree) RenderTree() []string {// <<<
 	return Lines
 }// >>>
 
+func (self *Tree) GetVisibleNodes() []*Node {// <<<
+	// returns the nodes in the same order as the lines of RenderTree()
+	var Nodes []*Node
+	var VisibleNodesHelper func(n *Node)
+
+	VisibleNodesHelper = func(n *Node) {
+
+		Nodes = append(Nodes, n)
+
+		if n.hidechildren == false {
+			for _,c := range n.children {
+				if c.hidenode == false {
+					VisibleNodesHelper(c)
+				}
+			}
+		}
+	}
+
+	VisibleNodesHelper(&self.Node)
+
+	return Nodes
+}// >>>
+
 // vim: fdm=marker fmr=<<<,>>>
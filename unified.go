@@ -0,0 +1,88 @@
+package main
+
+// imports <<<
+import (
+	"fmt"
+	"strings"
+	"github.com/charmbracelet/lipgloss"
+	"diffee/tree"
+) // >>>
+
+// Variables <<<
+var (
+	// status markers of the unified layout, like git status for whole trees
+	UnifiedMarkers = map[string]string{
+		"left-orphan":  "-",
+		"right-orphan": "+",
+		"different":    "~",
+		"same":         "=",
+	}
+)
+// >>>
+
+func getUnifiedInfo(E *Entry, left string, right string) string {// <<<
+	// the info of both sides is separated by a bar if it differs
+	_, _, LeftInfo  := getDecoration(E, left, Arg_Info)
+	_, _, RightInfo := getDecoration(E, right, Arg_Info)
+
+	switch {
+	case E.IsMissing[left]:
+		return RightInfo
+	case E.IsMissing[right], LeftInfo == RightInfo:
+		return LeftInfo
+	}
+	return LeftInfo + " |" + RightInfo
+}// >>>
+
+func decorateUnifiedText(E *Entry, left string, right string) string {// <<<
+	var Status string         = getEntryStatus(E, left, right)
+	var Style  lipgloss.Style = lipgloss.NewStyle()
+
+	switch Status {
+	case "left-orphan":
+		Style = StyleRemoved
+		if E.MovePath != "" {
+			Style = StyleMoved
+		}
+	case "right-orphan":
+		Style = StyleAdded
+		if E.MovePath != "" {
+			Style = StyleMoved
+		}
+	case "different":
+		Style = StyleDiff
+	}
+
+	return Style.Render(UnifiedMarkers[Status] + " " + E.Name) + getUnifiedInfo(E, left, right)
+}// >>>
+
+func setUnifiedText(node *tree.Node, left string, right string) {// <<<
+	for _, c := range node.GetChildren() {
+		if E, IsEntry := c.GetData().(*Entry); IsEntry {
+			c.SetText(decorateUnifiedText(E, left, right))
+		}
+		setUnifiedText(c, left, right)
+	}
+}// >>>
+
+func printUnified(contents *[]Entry) {// <<<
+	var Left  string = "left"
+	var Right string = "right"
+
+	if Arg_Swap {
+		Left, Right = Right, Left
+	}
+
+	// the second tree is only needed by filterTrees(), both trees are built from the same slice
+	var Tree      = convertSliceToTree(contents, Left)
+	var OtherTree = convertSliceToTree(contents, Right)
+
+	filterTrees(&Tree.Node, &OtherTree.Node)
+	setUnifiedText(&Tree.Node, Left, Right)
+
+	Tree.Node.SetText(StyleRoot.Render((*contents)[0].Path[Left]) + " ↔ " + StyleRoot.Render((*contents)[0].Path[Right]))
+
+	fmt.Println(strings.Join(Tree.SetRenderStyle(tree.RenderTreeStyle).RenderTree(), "\n"))
+}// >>>
+
+// vim: fdm=marker fmr=<<<,>>>